@@ -0,0 +1,182 @@
+package filer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ConflictPolicy SaveTo 的目标文件已存在时的处理方式
+type ConflictPolicy int
+
+const (
+	ConflictOverwrite  ConflictPolicy = iota // 覆盖已有文件（默认）
+	ConflictFail                             // 返回 ErrExists
+	ConflictSkip                             // 不写入，直接返回已有文件的路径
+	ConflictRename                           // 自动改名为 "name (1).ext"、"name (2).ext" ...
+	ConflictRenameDash                       // 自动改名为 "name-1.ext"、"name-2.ext" ...
+)
+
+// maxRenameAttempts 自动改名时的最大尝试次数
+const maxRenameAttempts = 10000
+
+// WithSyncDir 原子保存时，在重命名后再 fsync 所在目录，保证重命名本身在断电后也已落盘（Windows 下忽略）
+func WithSyncDir(enabled bool) Option {
+	return func(o *options) {
+		o.syncDir = enabled
+	}
+}
+
+// WithConflictPolicy 设置 SaveTo 的目标文件已存在时的处理方式，默认 ConflictOverwrite
+func WithConflictPolicy(policy ConflictPolicy) Option {
+	return func(o *options) {
+		o.conflict = policy
+	}
+}
+
+// writeFileAtomic 先写入同目录下的临时文件并 fsync，再重命名为 filename。
+// 任一步失败都会删除临时文件，目标路径上要么是旧内容，要么是完整的新内容，读者不会看到半写入的文件。
+func writeFileAtomic(filename string, write func(w io.Writer) error, syncDir bool) error {
+	_, _, err := writeFileWithPolicy(filename, write, ConflictOverwrite, syncDir)
+	return err
+}
+
+// writeFileWithPolicy 与 writeFileAtomic 相同，但按 policy 处理已存在的目标文件，返回最终写入（或跳过时已存在）的路径，
+// 以及是否确实写入了新文件（ConflictSkip 跳过时为 false）。
+// 除 ConflictOverwrite 外均通过硬链接“不覆盖地”落盘，并发保存同名文件时不会相互覆盖。
+func writeFileWithPolicy(filename string, write func(w io.Writer) error, policy ConflictPolicy, syncDir bool) (string, bool, error) {
+	if policy == ConflictFail || policy == ConflictSkip {
+		// 提前检查，避免无谓地读取整个数据流
+		if _, err := os.Lstat(filename); err == nil {
+			if policy == ConflictSkip {
+				return filename, false, nil
+			}
+			return filename, false, ErrExists
+		}
+	}
+
+	tmpName, err := writeTempFile(filename, write)
+	if err != nil {
+		return filename, false, err
+	}
+	finalName, written, err := commitTempFile(tmpName, filename, policy)
+	if err != nil {
+		_ = os.Remove(tmpName)
+		return finalName, false, err
+	}
+	if syncDir && written {
+		if err = syncDirectory(filepath.Dir(finalName)); err != nil {
+			return finalName, written, err
+		}
+	}
+	return finalName, written, nil
+}
+
+// writeTempFile 在 filename 所在目录创建临时文件，写入并 fsync 后返回其路径；失败时删除临时文件
+func writeTempFile(filename string, write func(w io.Writer) error) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmpName)
+		}
+	}()
+
+	// CreateTemp 创建的文件权限为 0600，与 os.Create 的常见结果保持一致
+	if err = tmp.Chmod(0644); err != nil {
+		return "", err
+	}
+	if err = write(tmp); err != nil {
+		return "", err
+	}
+	if err = tmp.Sync(); err != nil {
+		return "", err
+	}
+	if err = tmp.Close(); err != nil {
+		return "", err
+	}
+	return tmpName, nil
+}
+
+// commitTempFile 按 policy 将临时文件移动到最终路径，ConflictSkip 因目标已存在而放弃时 written 为 false
+func commitTempFile(tmpName, filename string, policy ConflictPolicy) (finalName string, written bool, err error) {
+	switch policy {
+	case ConflictFail, ConflictSkip:
+		err = linkNoReplace(tmpName, filename)
+		if errors.Is(err, fs.ErrExist) {
+			_ = os.Remove(tmpName)
+			if policy == ConflictSkip {
+				return filename, false, nil
+			}
+			return filename, false, ErrExists
+		}
+		return filename, err == nil, err
+	case ConflictRename, ConflictRenameDash:
+		for i := 0; i <= maxRenameAttempts; i++ {
+			candidate := renameCandidate(filename, i, policy)
+			err = linkNoReplace(tmpName, candidate)
+			if errors.Is(err, fs.ErrExist) {
+				continue
+			}
+			return candidate, err == nil, err
+		}
+		return filename, false, fmt.Errorf("%w, no free name after %d attempts", ErrExists, maxRenameAttempts)
+	default:
+		err = os.Rename(tmpName, filename)
+		return filename, err == nil, err
+	}
+}
+
+// linkNoReplace 在 target 不存在时把 tmpName 移动到 target，已存在时返回 fs.ErrExist。
+// 优先使用硬链接（原子且不覆盖）；文件系统不支持硬链接时退回到检查后重命名。
+func linkNoReplace(tmpName, target string) error {
+	err := os.Link(tmpName, target)
+	if err == nil {
+		return os.Remove(tmpName)
+	}
+	if errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	if _, serr := os.Lstat(target); serr == nil {
+		return fs.ErrExist
+	}
+	return os.Rename(tmpName, target)
+}
+
+// renameCandidate 返回第 i 个候选文件名，i 为 0 时为原文件名
+func renameCandidate(filename string, i int, policy ConflictPolicy) string {
+	if i == 0 {
+		return filename
+	}
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	if policy == ConflictRenameDash {
+		return fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	return fmt.Sprintf("%s (%d)%s", base, i, ext)
+}
+
+// syncDirectory fsync 目录，使其中的新建、重命名操作落盘
+func syncDirectory(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	if err = d.Sync(); err != nil {
+		_ = d.Close()
+		return err
+	}
+	return d.Close()
+}
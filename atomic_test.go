@@ -0,0 +1,162 @@
+package filer_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSaveTo_AtomicOnFailure 验证下载中途失败时目标文件保持原样，且不残留临时文件。
+func TestSaveTo_AtomicOnFailure(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 64*1024)
+	srv := droppingServer(t, content, func() string { return `"v1"` })
+	defer srv.Close()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "photo.jpg")
+	require.NoError(t, os.WriteFile(filename, []byte("old"), 0644))
+
+	f := filer.NewFiler()
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open(srv.URL+"/photo.jpg"))
+	_, err := f.SaveTo(filename)
+	require.Error(t, err)
+
+	got, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(got))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+// TestSaveTo_AtomicReplace 验证成功时替换已有文件，并支持 fsync 目录。
+func TestSaveTo_AtomicReplace(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "a.txt")
+	require.NoError(t, os.WriteFile(filename, []byte("old content"), 0644))
+
+	f := filer.NewFiler(filer.WithSyncDir(true))
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open([]byte("new")))
+	_, err := f.SaveTo(filename)
+	require.NoError(t, err)
+
+	got, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(got))
+	info, err := os.Stat(filename)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+// TestSaveTo_ConflictPolicy 验证目标文件已存在时各策略的行为与返回路径。
+func TestSaveTo_ConflictPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   filer.ConflictPolicy
+		wantName string
+		wantErr  error
+		want     string
+	}{
+		{"overwrite", filer.ConflictOverwrite, "avatar.png", nil, "new"},
+		{"fail", filer.ConflictFail, "avatar.png", filer.ErrExists, "old"},
+		{"skip", filer.ConflictSkip, "avatar.png", nil, "old"},
+		{"rename", filer.ConflictRename, "avatar (1).png", nil, "new"},
+		{"rename dash", filer.ConflictRenameDash, "avatar-1.png", nil, "new"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			filename := filepath.Join(dir, "avatar.png")
+			require.NoError(t, os.WriteFile(filename, []byte("old"), 0644))
+
+			f := filer.NewFiler(filer.WithConflictPolicy(tt.policy))
+			defer func() { _ = f.Close() }()
+			require.NoError(t, f.Open([]byte("new")))
+			saved, err := f.SaveTo(filename)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, saved)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, filepath.Join(dir, tt.wantName), saved)
+			}
+
+			got, err := os.ReadFile(filepath.Join(dir, tt.wantName))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+			old, err := os.ReadFile(filename)
+			require.NoError(t, err)
+			if tt.policy != filer.ConflictOverwrite {
+				assert.Equal(t, "old", string(old))
+			}
+		})
+	}
+}
+
+// TestSaveTo_ConflictRenameSequence 验证连续保存同名文件时依次递增编号。
+func TestSaveTo_ConflictRenameSequence(t *testing.T) {
+	dir := t.TempDir()
+	var saved []string
+	for i := 0; i < 3; i++ {
+		f := filer.NewFiler(filer.WithConflictPolicy(filer.ConflictRename))
+		require.NoError(t, f.Open([]byte("data")))
+		name, err := f.SaveTo(filepath.Join(dir, "report.pdf"))
+		require.NoError(t, err)
+		saved = append(saved, filepath.Base(name))
+		_ = f.Close()
+	}
+	assert.Equal(t, []string{"report.pdf", "report (1).pdf", "report (2).pdf"}, saved)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
+
+// TestImagerSaveTo_ConflictPolicy 验证 Imager.SaveTo 同样按 WithConflictPolicy 处理已存在的目标文件。
+func TestImagerSaveTo_ConflictPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   filer.ConflictPolicy
+		wantName string
+		wantErr  error
+	}{
+		{"fail", filer.ConflictFail, "", filer.ErrExists},
+		{"skip", filer.ConflictSkip, "avatar.png", nil},
+		{"rename", filer.ConflictRename, "avatar (1).png", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			filename := filepath.Join(dir, "avatar.png")
+			require.NoError(t, os.WriteFile(filename, []byte("old"), 0644))
+
+			f := filer.NewFiler(filer.WithConflictPolicy(tt.policy))
+			defer func() { _ = f.Close() }()
+			require.NoError(t, f.Open(pngFixture(8, 8)))
+			img, err := f.Imager()
+			require.NoError(t, err)
+			require.NoError(t, img.Resize(2, 2))
+
+			err = img.SaveTo(filename)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, img.SavedPath())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, filepath.Join(dir, tt.wantName), img.SavedPath())
+			}
+			old, err := os.ReadFile(filename)
+			require.NoError(t, err)
+			assert.Equal(t, "old", string(old))
+		})
+	}
+}
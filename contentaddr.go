@@ -0,0 +1,216 @@
+package filer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// contentKey 内容寻址的相对路径：SHA-256 十六进制摘要的前两级各 2 个字符作为子目录，如 "ab/cd/abcdef...jpg"
+func contentKey(sum, ext string) string {
+	return sum[:2] + "/" + sum[2:4] + "/" + sum + strings.ToLower(ext)
+}
+
+// SaveToContentAddressed 按内容寻址保存到 dir 下，文件保存为 dir/ab/cd/<sha256><ext>。
+// 可 Seek 的数据源先计算摘要，相同内容已存在时不再写入，直接返回已有文件路径；
+// 不可 Seek 的流在复制到临时文件时同步计算摘要，已存在时丢弃临时文件。Uri() 规则与 SaveTo 相同。
+func (f *Filer) SaveToContentAddressed(dir string) (string, error) {
+	savedPath, err := f.saveToContentAddressed(dir)
+	if err != nil {
+		if savedPath == "" {
+			savedPath = strings.TrimSpace(dir)
+		}
+		return "", f.wrapError("save", savedPath, err)
+	}
+	return savedPath, nil
+}
+
+// saveToContentAddressed 执行 SaveToContentAddressed，出错时 savedPath 为已确定的目标路径（可能为空）
+func (f *Filer) saveToContentAddressed(dir string) (savedPath string, err error) {
+	if f.readCloser == nil {
+		return "", ErrNotOpened
+	}
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return "", ErrEmptyPath
+	}
+	dir = filepath.Clean(filepath.FromSlash(strings.ReplaceAll(dir, `\`, `/`)))
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("make %s directory failed, %w", dir, err)
+	}
+	if _, err = f.fileURI(filepath.Join(dir, "content")); err != nil {
+		return "", err
+	}
+	if _, ok := f.readCloser.(io.Seeker); ok {
+		return f.saveSeekableContentAddressed(dir)
+	}
+	if err = f.seekStart(); err != nil {
+		return "", err
+	}
+
+	// 不可 Seek 的流摘要在写完后才知道，先写入 dir 下的临时文件，再链接到最终路径
+	// 配额计入 dir 本身（而不是 ab/cd 子目录），大小未知，在写入过程中检查
+	quota, err := f.checkQuotaSize(filepath.Join(dir, "content"), -1, false)
+	if err != nil {
+		return "", err
+	}
+	r, finish, err := f.hashingReader()
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	var qw *quotaWriter
+	tmpName, err := writeTempFile(filepath.Join(dir, "content"), func(w io.Writer) error {
+		if quota != nil {
+			qw = &quotaWriter{w: w, st: quota}
+			w = qw
+		}
+		if _, err := io.Copy(io.MultiWriter(w, h), r); err != nil {
+			return fmt.Errorf("write %s file data failed, %w", dir, err)
+		}
+		return finish()
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	f.storeHashes(Hashes{HashSHA256: sum})
+	filename := filepath.Join(dir, filepath.FromSlash(contentKey(sum, f.Ext())))
+	if err = os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		_ = os.Remove(tmpName)
+		return filename, fmt.Errorf("make %s directory failed, %w", filepath.Dir(filename), err)
+	}
+	// 已存在时 ConflictSkip 会删除临时文件并返回已有路径
+	filename, written, err := commitTempFile(tmpName, filename, ConflictSkip)
+	if err != nil {
+		_ = os.Remove(tmpName)
+		return filename, err
+	}
+	if written && qw != nil {
+		if err = qw.commit(); err != nil {
+			return filename, err
+		}
+	}
+	if f.opts.syncDir {
+		if err = syncDirectory(filepath.Dir(filename)); err != nil {
+			return filename, err
+		}
+	}
+	f.uri, _ = f.fileURI(filename)
+	return filename, nil
+}
+
+// saveSeekableContentAddressed 可 Seek 的数据源先计算摘要，相同内容已存在时直接返回已有路径，不写临时文件
+func (f *Filer) saveSeekableContentAddressed(dir string) (string, error) {
+	sum, err := f.verifiedSHA256()
+	if err != nil {
+		return "", err
+	}
+	filename := filepath.Join(dir, filepath.FromSlash(contentKey(sum, f.Ext())))
+	if err = os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return filename, fmt.Errorf("make %s directory failed, %w", filepath.Dir(filename), err)
+	}
+	if _, err = os.Lstat(filename); err == nil {
+		f.uri, _ = f.fileURI(filename)
+		return filename, nil
+	}
+	size, err := f.sizeOf()
+	if err != nil {
+		return filename, err
+	}
+	quota, err := f.checkQuotaSize(filepath.Join(dir, "content"), size, false)
+	if err != nil {
+		return filename, err
+	}
+	// 并发保存相同内容时 ConflictSkip 保证不会相互覆盖
+	var qw *quotaWriter
+	filename, written, err := writeFileWithPolicy(filename, func(w io.Writer) error {
+		if quota != nil {
+			qw = &quotaWriter{w: w, st: quota}
+			w = qw
+		}
+		if _, err := io.Copy(w, f.reader()); err != nil {
+			return fmt.Errorf("write %s file data failed, %w", filename, err)
+		}
+		return nil
+	}, ConflictSkip, f.opts.syncDir)
+	if err != nil {
+		return filename, err
+	}
+	if written && qw != nil {
+		if err = qw.commit(); err != nil {
+			return filename, err
+		}
+	}
+	f.uri, _ = f.fileURI(filename)
+	return filename, nil
+}
+
+// verifiedSHA256 从头读取一遍内容计算 SHA-256，同时计算 WithHashes 并校验期望的摘要，读取后回到起始位置
+func (f *Filer) verifiedSHA256() (string, error) {
+	if err := f.seekStart(); err != nil {
+		return "", err
+	}
+	r, finish, err := f.hashingReader()
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err = io.Copy(h, r); err != nil {
+		return "", err
+	}
+	if err = finish(); err != nil {
+		return "", err
+	}
+	if err = f.seekStart(); err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	f.storeHashes(Hashes{HashSHA256: sum})
+	return sum, nil
+}
+
+// SaveToStorageContentAddressed 按内容寻址写入 storage：key 为 prefix/ab/cd/<sha256><ext>（prefix 可为空）。
+// storage 中已存在该 key 时跳过写入，直接返回 key；Uri() 更新为 storage.URL(key)。非 Seek 流会先缓冲为内存流以计算摘要。
+func (f *Filer) SaveToStorageContentAddressed(ctx context.Context, storage Storage, prefix string) (string, error) {
+	key, err := f.saveToStorageContentAddressed(ctx, storage, prefix)
+	if err != nil {
+		if key == "" {
+			key = prefix
+		}
+		return "", f.wrapError("save", key, err)
+	}
+	return key, nil
+}
+
+// saveToStorageContentAddressed 执行 SaveToStorageContentAddressed，出错时返回已确定的 key
+func (f *Filer) saveToStorageContentAddressed(ctx context.Context, storage Storage, prefix string) (string, error) {
+	if f.readCloser == nil {
+		return "", ErrNotOpened
+	}
+	sum, err := f.contentSHA256()
+	if err != nil {
+		return "", err
+	}
+	key := contentKey(sum, f.Ext())
+	if prefix = strings.TrimSpace(strings.ReplaceAll(prefix, `\`, "/")); prefix != "" {
+		key = prefix + "/" + key
+	}
+	if key, err = cleanKey(key); err != nil {
+		return "", err
+	}
+	exists, err := storage.Exists(ctx, key)
+	if err != nil {
+		return key, err
+	}
+	if exists {
+		f.uri = storage.URL(key)
+		return key, nil
+	}
+	return key, f.putToStorage(ctx, storage, key)
+}
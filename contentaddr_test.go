@@ -0,0 +1,124 @@
+package filer_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSaveToContentAddressed 验证按摘要分目录保存，相同内容只写一次且返回已有路径。
+func TestSaveToContentAddressed(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("product image bytes")
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	want := filepath.Join(dir, hash[:2], hash[2:4], hash)
+
+	for i := 0; i < 3; i++ {
+		f := filer.NewFiler()
+		// 非 Seek 流同样可以边复制边计算摘要
+		require.NoError(t, f.Open(struct{ io.Reader }{bytes.NewReader(content)}))
+		saved, err := f.SaveToContentAddressed(dir)
+		require.NoError(t, err)
+		assert.Equal(t, want, saved)
+		_ = f.Close()
+	}
+
+	got, err := os.ReadFile(want)
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+	var files []string
+	require.NoError(t, filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+		return err
+	}))
+	assert.Equal(t, []string{want}, files)
+}
+
+// TestSaveToContentAddressed_ExistingNotOverwritten 验证已存在的对象不会被重写。
+func TestSaveToContentAddressed_ExistingNotOverwritten(t *testing.T) {
+	dir := t.TempDir()
+	f := filer.NewFiler()
+	require.NoError(t, f.Open([]byte("same")))
+	saved, err := f.SaveToContentAddressed(dir)
+	require.NoError(t, err)
+	info, err := os.Stat(saved)
+	require.NoError(t, err)
+
+	f2 := filer.NewFiler()
+	require.NoError(t, f2.Open([]byte("same")))
+	saved2, err := f2.SaveToContentAddressed(dir)
+	require.NoError(t, err)
+	assert.Equal(t, saved, saved2)
+	info2, err := os.Stat(saved2)
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(info, info2))
+}
+
+// TestSaveToContentAddressed_DuplicateNotWritten 验证可 Seek 的数据源内容已存在时不写临时文件（目录不被修改）。
+func TestSaveToContentAddressed_DuplicateNotWritten(t *testing.T) {
+	dir := t.TempDir()
+	f := filer.NewFiler()
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open([]byte("same")))
+	saved, err := f.SaveToContentAddressed(dir)
+	require.NoError(t, err)
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, d := range []string{dir, filepath.Dir(saved)} {
+		require.NoError(t, os.Chtimes(d, past, past))
+	}
+	require.NoError(t, f.Open([]byte("same")))
+	saved2, err := f.SaveToContentAddressed(dir)
+	require.NoError(t, err)
+	assert.Equal(t, saved, saved2)
+	for _, d := range []string{dir, filepath.Dir(saved)} {
+		info, err := os.Stat(d)
+		require.NoError(t, err)
+		assert.True(t, info.ModTime().Equal(past), "%s was modified", d)
+	}
+
+	// 已存在时仍校验期望的摘要
+	f2 := filer.NewFiler(filer.WithVerifyChecksum(filer.HashSHA256, strings.Repeat("0", 64)))
+	defer func() { _ = f2.Close() }()
+	require.NoError(t, f2.Open([]byte("same")))
+	_, err = f2.SaveToContentAddressed(dir)
+	assert.ErrorIs(t, err, filer.ErrChecksumMismatch)
+}
+
+// TestSaveToStorageContentAddressed 验证存储后端已存在对象时跳过写入。
+func TestSaveToStorageContentAddressed(t *testing.T) {
+	storage := filer.NewMemoryStorage("https://cdn.example.com")
+	f := filer.NewFiler()
+	require.NoError(t, f.Open([]byte("hello")))
+	key, err := f.SaveToStorageContentAddressed(t.Context(), storage, "images")
+	require.NoError(t, err)
+	assert.Equal(t, "images/2c/f2/2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824.txt", key)
+	assert.Equal(t, "https://cdn.example.com/"+key, f.Uri())
+
+	// 已存在的对象不会被覆盖
+	require.NoError(t, storage.Put(t.Context(), key, strings.NewReader("marker"), filer.PutOptions{Size: 6}))
+	f2 := filer.NewFiler()
+	require.NoError(t, f2.Open([]byte("hello")))
+	key2, err := f2.SaveToStorageContentAddressed(t.Context(), storage, "images")
+	require.NoError(t, err)
+	assert.Equal(t, key, key2)
+	rc, err := storage.Get(t.Context(), key)
+	require.NoError(t, err)
+	defer func() { _ = rc.Close() }()
+	got, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "marker", string(got))
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package filer
+
+// diskFree 当前平台不支持查询可用空间，返回 ok 为 false 以跳过检查
+func diskFree(string) (int64, bool, error) {
+	return 0, false, nil
+}
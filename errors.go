@@ -0,0 +1,103 @@
+package filer
+
+import (
+	"errors"
+	"strings"
+)
+
+// 可通过 errors.Is 判断的错误
+var (
+	ErrNilContext        = errors.New("filer: nil context")
+	ErrNilSource         = errors.New("filer: open data is nil")
+	ErrUnsupportedSource = errors.New("filer: unsupported file format")
+	ErrSourceMismatch    = errors.New("filer: source does not match the requested type")
+	ErrInvalidBase64     = errors.New("filer: invalid base64 format")
+	ErrHTTPStatus        = errors.New("filer: unexpected response status")
+	ErrNotOpened         = errors.New("filer: no read file")
+	ErrNotSeekable       = errors.New("filer: readCloser is not a seeker")
+	ErrNotImage          = errors.New("filer: not an image")
+	ErrEmptyPath         = errors.New("filer: path is empty")
+	ErrExists            = errors.New("filer: file already exists")
+	ErrUnknownFormat     = errors.New("imager: cannot decide output format")
+	ErrTooLarge          = errors.New("filer: file too large")
+	ErrRemoteChanged     = errors.New("filer: remote file changed during download")
+	ErrChecksumMismatch  = errors.New("filer: checksum mismatch")
+	ErrUnsupportedHash   = errors.New("filer: unsupported hash algorithm")
+	ErrOutsideRoot       = errors.New("filer: path is outside the storage root")
+	ErrObjectNotFound    = errors.New("filer: object not found")
+	ErrInvalidKey        = errors.New("filer: invalid storage key")
+	ErrSignatureInvalid  = errors.New("filer: invalid url signature")
+	ErrSignatureExpired  = errors.New("filer: signed url expired")
+	ErrQuotaExceeded     = errors.New("filer: quota exceeded")
+	ErrValidation        = errors.New("filer: validation failed")
+	ErrExtMismatch       = errors.New("filer: extension does not match content")
+	ErrImageTooLarge     = errors.New("filer: image dimensions too large")
+)
+
+// HTTPStatusError 网络源返回了非预期的状态码，errors.Is(err, ErrHTTPStatus) 成立
+type HTTPStatusError struct {
+	StatusCode int    // 状态码，如 404
+	Status     string // 状态行，如 "404 Not Found"
+}
+
+func (e *HTTPStatusError) Error() string {
+	return "filer: response status " + e.Status
+}
+
+// Is 使 errors.Is(err, ErrHTTPStatus) 成立
+func (e *HTTPStatusError) Is(target error) bool {
+	return target == ErrHTTPStatus
+}
+
+// FilerError 记录出错的操作、数据源类型与路径，Err 为底层错误，可配合 errors.Is/errors.As 使用
+type FilerError struct {
+	Op     string     // 操作，如 open、read、size、save、imager
+	Source SourceType // 数据源类型，如 SourceNetwork、SourceLocalFile
+	Path   string     // 相关路径：打开时为 URL 或文件路径，保存时为目标路径；文本、Data URI 等为空
+	Err    error
+}
+
+func (e *FilerError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("filer: ")
+	sb.WriteString(e.Op)
+	if e.Path != "" {
+		sb.WriteString(" ")
+		sb.WriteString(e.Path)
+	}
+	if e.Err != nil {
+		sb.WriteString(": ")
+		msg := e.Err.Error()
+		if s, ok := strings.CutPrefix(msg, "filer: "); ok {
+			msg = s
+		}
+		sb.WriteString(msg)
+	}
+	return sb.String()
+}
+
+func (e *FilerError) Unwrap() error {
+	return e.Err
+}
+
+// wrapError 将 err 包装为 *FilerError，已是 *FilerError 时原样返回
+func (f *Filer) wrapError(op, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var fe *FilerError
+	if errors.As(err, &fe) {
+		return err
+	}
+	return &FilerError{Op: op, Source: f.typ, Path: path, Err: err}
+}
+
+// sourcePath 返回可安全写入错误信息的源路径，文本与 Data URI 内容不输出
+func (f *Filer) sourcePath() string {
+	switch f.typ {
+	case SourceNetwork, SourceLocalFile, SourceOSFile, SourceFSFile:
+		return f.path
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,56 @@
+package filer_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFilerError_HTTPStatus 验证非 200 响应可通过 ErrHTTPStatus/HTTPStatusError/FilerError 识别。
+func TestFilerError_HTTPStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	err := filer.NewFiler().Open(srv.URL + "/missing.jpg")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, filer.ErrHTTPStatus)
+
+	var statusErr *filer.HTTPStatusError
+	require.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+
+	var fe *filer.FilerError
+	require.True(t, errors.As(err, &fe))
+	assert.Equal(t, "open", fe.Op)
+	assert.Equal(t, filer.SourceNetwork, fe.Source)
+	assert.Equal(t, srv.URL+"/missing.jpg", fe.Path)
+	assert.Equal(t, "filer: open "+srv.URL+"/missing.jpg: response status 404 Not Found", err.Error())
+}
+
+// TestFilerError_Sentinels 验证常见失败场景返回对应的哨兵错误。
+func TestFilerError_Sentinels(t *testing.T) {
+	f := filer.NewFiler()
+	_, err := f.Body()
+	assert.ErrorIs(t, err, filer.ErrNotOpened)
+
+	assert.ErrorIs(t, f.Open(nil), filer.ErrNilSource)
+	assert.ErrorIs(t, f.Open(42), filer.ErrUnsupportedSource)
+
+	require.NoError(t, f.Open([]byte("not an image")))
+	_, err = f.Imager()
+	assert.ErrorIs(t, err, filer.ErrNotImage)
+
+	_, err = f.SaveTo("  ")
+	assert.ErrorIs(t, err, filer.ErrEmptyPath)
+	var fe *filer.FilerError
+	require.True(t, errors.As(err, &fe))
+	assert.Equal(t, "save", fe.Op)
+	assert.Equal(t, filer.SourceBytes, fe.Source)
+}
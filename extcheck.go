@@ -0,0 +1,128 @@
+package filer
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ExtMismatch 声明的扩展名与按内容识别的结果不一致
+type ExtMismatch struct {
+	Declared string // 来自文件名、表单头或 URL 的扩展名
+	Detected string // 按内容识别的扩展名
+	MIMEType string // 按内容识别的类型
+}
+
+// ExtMismatchError 严格模式下扩展名与内容不一致
+type ExtMismatchError struct {
+	ExtMismatch
+}
+
+func (e *ExtMismatchError) Error() string {
+	return fmt.Sprintf("filer: extension %s does not match content %s (%s)", e.Declared, e.MIMEType, e.Detected)
+}
+
+// Is 使 errors.Is(err, ErrExtMismatch) 成立
+func (e *ExtMismatchError) Is(target error) bool {
+	return target == ErrExtMismatch
+}
+
+// WithStrictExt 严格模式：Open 时声明的扩展名与内容不一致则关闭文件并返回 *ExtMismatchError，
+// SaveTo 时目标文件的扩展名与内容不一致同样拒绝，防止伪装扩展名的文件被保存。
+func WithStrictExt(enabled bool) Option {
+	return func(o *options) {
+		o.strictExt = enabled
+	}
+}
+
+// inconclusiveMIMETypes 按内容无法确定具体格式的类型，仅当声明的扩展名本身为文本或未知类型时视为一致
+var inconclusiveMIMETypes = []string{"text/plain", "application/octet-stream"}
+
+// textualMIMETypes 内容为纯文本的 application/* 类型
+var textualMIMETypes = []string{"application/json", "application/xml", "application/javascript", "application/x-sh", "application/x-yaml", "application/yaml"}
+
+// ExtMismatch 比较声明的扩展名（文件名、表单头或 URL）与按内容识别的结果，不一致时返回 true。
+// 未声明扩展名时视为一致；内容为纯文本或无法识别时，仅当声明的扩展名为文本（.txt、.csv 等）或未知类型时视为一致，
+// 因此内容为 PHP 脚本的 .jpg 会被识别为不一致。不可 Seek 的流会先缓冲为内存流。
+func (f *Filer) ExtMismatch() (ExtMismatch, bool) {
+	m, err := f.extMismatch(f.declaredExt())
+	return m, err == nil && m.Detected != ""
+}
+
+// declaredExt 文件名、表单头或 URL 给出的扩展名（小写）
+func (f *Filer) declaredExt() string {
+	if f.name != "" {
+		if e := path.Ext(strings.ReplaceAll(f.name, `\`, "/")); plausibleFilenameExt(e) {
+			return strings.ToLower(e)
+		}
+	}
+	if f.possibleExt != "" {
+		return strings.ToLower(f.possibleExt)
+	}
+	if f.typ == SourceNetwork {
+		return strings.ToLower(f.ext)
+	}
+	return ""
+}
+
+// extMismatch 比较 declared 与内容，一致时返回零值
+func (f *Filer) extMismatch(declared string) (ExtMismatch, error) {
+	if declared == "" || f.readCloser == nil {
+		return ExtMismatch{}, nil
+	}
+	if err := f.ensureSeekable(); err != nil {
+		return ExtMismatch{}, err
+	}
+	r, err := f.sniff()
+	if err != nil || r.mimeType == "" {
+		return ExtMismatch{}, err
+	}
+	mimeType := mimeBaseType(r.mimeType)
+	if slices.Contains(inconclusiveMIMETypes, mimeType) {
+		if f.looseExt(declared) {
+			return ExtMismatch{}, nil
+		}
+	} else if f.mimeRegistry().compatibleExt(r, declared) {
+		return ExtMismatch{}, nil
+	}
+	detected := strings.ToLower(sniffedExt(f.mimeRegistry(), r, declared))
+	if detected == "" && mimeType == "application/octet-stream" {
+		detected = ".bin"
+	}
+	if detected == "" || detected == declared {
+		return ExtMismatch{}, nil
+	}
+	return ExtMismatch{Declared: declared, Detected: detected, MIMEType: mimeType}, nil
+}
+
+// looseExt 判断 ext 是否为文本或未知类型，此类扩展名无法按内容确定是否一致
+func (f *Filer) looseExt(ext string) bool {
+	mimeType := mimeBaseType(f.mimeRegistry().contentType(ext))
+	return mimeType == "application/octet-stream" ||
+		strings.HasPrefix(mimeType, "text/") ||
+		strings.HasSuffix(mimeType, "+xml") ||
+		strings.HasSuffix(mimeType, "+json") ||
+		slices.Contains(textualMIMETypes, mimeType)
+}
+
+// checkStrictExt 严格模式下检查 declared 与内容是否一致
+func (f *Filer) checkStrictExt(declared string) error {
+	if !f.opts.strictExt {
+		return nil
+	}
+	m, err := f.extMismatch(strings.ToLower(declared))
+	if err != nil {
+		return err
+	}
+	if m.Detected != "" {
+		return &ExtMismatchError{ExtMismatch: m}
+	}
+	return nil
+}
+
+// checkStrictTarget 严格模式下检查 SaveTo 目标文件的扩展名
+func (f *Filer) checkStrictTarget(filename string) error {
+	return f.checkStrictExt(filepath.Ext(filename))
+}
@@ -0,0 +1,125 @@
+package filer_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExtMismatch 验证声明的扩展名与内容不一致时的识别结果。
+func TestExtMismatch(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		file     string
+		content  []byte
+		mismatch bool
+		detected string
+	}{
+		{"html as jpg", "photo.jpg", []byte("<!DOCTYPE html><html><script>alert(1)</script></html>"), true, ".html"},
+		{"pe as jpg", "photo.jpg", peFixture(), true, ".exe"},
+		{"mz text as txt", "notes.txt", []byte("MZ-2000 service manual\n"), false, ""},
+		{"png as jpg", "photo.JPG", pngFixture(2, 2), true, ".png"},
+		{"real png", "photo.png", pngFixture(2, 2), false, ""},
+		{"jpeg alias", "photo.jpg", []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"), false, ""},
+		{"json as text", "data.json", []byte(`{"a":1}`), false, ""},
+		{"svg as xml", "icon.svg", []byte(`<?xml version="1.0"?><svg></svg>`), false, ""},
+		{"docx zip", "a.docx", []byte("PK\x03\x04\x14\x00\x00\x00"), false, ""},
+		{"no extension", "README", []byte("<html></html>"), false, ""},
+		{"php as jpg", "shell.jpg", []byte("<?php system($_GET['c']); ?>"), true, ".txt"},
+		{"binary as pdf", "doc.pdf", []byte("\x00\x01\x02\x03garbage"), true, ".bin"},
+		{"text as csv", "data.csv", []byte("a,b\n1,2\n"), false, ""},
+		{"text as log", "app.log", []byte("started\n"), false, ""},
+		{"binary unknown ext", "blob.dat", []byte("\x00\x01\x02\x03"), false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			require.NoError(t, os.WriteFile(path, tt.content, 0644))
+			f := filer.NewFiler()
+			defer func() { _ = f.Close() }()
+			require.NoError(t, f.Open(path))
+			m, mismatch := f.ExtMismatch()
+			assert.Equal(t, tt.mismatch, mismatch)
+			assert.Equal(t, tt.detected, m.Detected)
+			if mismatch {
+				assert.Equal(t, strings.ToLower(filepath.Ext(tt.file)), m.Declared)
+			}
+		})
+	}
+}
+
+// TestStrictExt 验证严格模式在 Open 与 SaveTo 时拒绝扩展名与内容不一致的文件。
+func TestStrictExt(t *testing.T) {
+	html := []byte("<html><body>not an image</body></html>")
+	f := filer.NewFiler(filer.WithStrictExt(true))
+	err := f.Open(formFileHeader(t, "avatar.jpg", html))
+	var mismatchErr *filer.ExtMismatchError
+	require.True(t, errors.As(err, &mismatchErr))
+	assert.ErrorIs(t, err, filer.ErrExtMismatch)
+	assert.Equal(t, ".jpg", mismatchErr.Declared)
+	assert.Equal(t, "text/html", mismatchErr.MIMEType)
+
+	dir := t.TempDir()
+	php := []byte("<?php system($_GET['c']); ?>")
+	path := filepath.Join(dir, "shell.jpg")
+	require.NoError(t, os.WriteFile(path, php, 0644))
+	f = filer.NewFiler(filer.WithStrictExt(true))
+	assert.ErrorIs(t, f.Open(path), filer.ErrExtMismatch)
+	f = filer.NewFiler(filer.WithStrictExt(true))
+	require.NoError(t, f.Open(php))
+	_, err = f.SaveTo(filepath.Join(dir, "x.jpg"))
+	assert.ErrorIs(t, err, filer.ErrExtMismatch)
+	_, err = f.SaveTo(filepath.Join(dir, "x.txt"))
+	assert.NoError(t, err)
+
+	f = filer.NewFiler(filer.WithStrictExt(true))
+	require.NoError(t, f.Open(pngFixture(2, 2)))
+	_, err = f.SaveTo(filepath.Join(dir, "a.gif"))
+	assert.ErrorIs(t, err, filer.ErrExtMismatch)
+	_, err = f.SaveTo(filepath.Join(dir, "a.png"))
+	assert.NoError(t, err)
+
+	// Imager.SaveTo 同样检查目标扩展名
+	img, err := f.Imager()
+	require.NoError(t, err)
+	assert.ErrorIs(t, img.SaveTo(filepath.Join(dir, "b.gif")), filer.ErrExtMismatch)
+	_, err = os.Stat(filepath.Join(dir, "b.gif"))
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, img.SaveTo(filepath.Join(dir, "b.png")))
+
+	// 写入存储后端时同样按 key 的扩展名检查
+	storage := filer.NewMemoryStorage("")
+	_, err = f.SaveToStorage(context.Background(), storage, "uploads/x.html")
+	assert.ErrorIs(t, err, filer.ErrExtMismatch)
+	assert.ErrorIs(t, img.SaveToStorage(context.Background(), storage, "uploads/x.html"), filer.ErrExtMismatch)
+	assert.Empty(t, storage.Keys())
+	_, err = f.SaveToStorage(context.Background(), storage, "uploads/x.png")
+	assert.NoError(t, err)
+}
+
+// formFileHeader 构造上传文件名为 filename 的 multipart.FileHeader
+func formFileHeader(t *testing.T, filename string, content []byte) *multipart.FileHeader {
+	t.Helper()
+	var b bytes.Buffer
+	writer := multipart.NewWriter(&b)
+	part, err := writer.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(&b, writer.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = form.RemoveAll() })
+	return form.File["file"][0]
+}
@@ -2,11 +2,13 @@ package filer
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"image"
 	"io"
+	"io/fs"
 	"mime"
 	"mime/multipart"
 	"net/http"
@@ -27,24 +29,28 @@ const (
 	dataURIPattern        = `^data:(?:[a-zA-Z]+\/[a-zA-Z0-9-.+]+)(?:;charset=[a-zA-Z0-9-]+)?;base64,[A-Za-z0-9+\/]+=*$`
 )
 
-// File type
+// SourceType 数据源类型，见 Filer.SourceType
+type SourceType string
+
+// 数据源类型
 const (
-	network       = "network"      // Network
-	base64Type    = "base64"       // Base64
-	localFilePath = "local-file"   // Local file
-	textContent   = "text-content" // Text content
-	osFile        = "os-file"      // Opened file handle
-	formFile      = "form-file"    // Form file
-	fileBytes     = "bytes"        // File bytes
+	SourceNetwork   SourceType = "network"      // Network
+	SourceBase64    SourceType = "base64"       // Base64
+	SourceLocalFile SourceType = "local-file"   // Local file
+	SourceText      SourceType = "text-content" // Text content
+	SourceOSFile    SourceType = "os-file"      // Opened file handle
+	SourceFormFile  SourceType = "form-file"    // Form file
+	SourceBytes     SourceType = "bytes"        // File bytes
+	SourceFSFile    SourceType = "fs-file"      // fs.File（含 OpenFS）
+	SourceReader    SourceType = "reader"       // Generic io.Reader
 )
 
 var (
-	rxBase64  *regexp.Regexp
-	rxDataURI *regexp.Regexp
+	rxBase64              *regexp.Regexp
+	rxDataURI             *regexp.Regexp
+	rxDispositionFilename = regexp.MustCompile(`(?i)filename\s*=\s*"?([^";]+)"?`)
 )
 
-var commonMimeTypeExt map[string]string
-
 // defaultHTTPClient 用于拉取网络文件，避免无限阻塞并统一超时策略。
 var defaultHTTPClient = &http.Client{
 	Timeout: 60 * time.Second,
@@ -53,72 +59,24 @@ var defaultHTTPClient = &http.Client{
 func init() {
 	rxBase64 = regexp.MustCompile(base64Pattern)
 	rxDataURI = regexp.MustCompile(dataURIPattern)
-	commonMimeTypeExt = map[string]string{
-		// 图片
-		"image/jpeg":    ".jpeg",
-		"image/png":     ".png",
-		"image/gif":     ".gif",
-		"image/webp":    ".webp",
-		"image/bmp":     ".bmp",
-		"image/svg+xml": ".svg",
-		"image/tiff":    ".tiff",
-		"image/x-icon":  ".ico",
-
-		// 文本
-		"text/plain":      ".txt",
-		"text/html":       ".html",
-		"text/css":        ".css",
-		"text/javascript": ".js",
-		"text/csv":        ".csv",
-		"text/xml":        ".xml",
-
-		// 应用
-		"application/json":            ".json",
-		"application/pdf":             ".pdf",
-		"application/zip":             ".zip",
-		"application/gzip":            ".gz",
-		"application/x-tar":           ".tar",
-		"application/rar":             ".rar",
-		"application/x-7z-compressed": ".7z",
-		"application/msword":          ".doc",
-		"application/vnd.openxmlformats-officedocument.wordprocessingml.document": ".docx",
-		"application/vnd.ms-excel": ".xls",
-		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": ".xlsx",
-		"application/vnd.mozilla.xul+xml":                                   ".xul",
-		"application/x-shockwave-flash":                                     ".swf",
-		"application/xhtml+xml":                                             ".xhtml",
-		"application/rtf":                                                   ".rtf",
-
-		// 音频
-		"audio/mpeg": ".mp3",
-		"audio/wav":  ".wav",
-		"audio/ogg":  ".ogg",
-		"audio/aac":  ".aac",
-		"audio/flac": ".flac",
-
-		// 视频
-		"video/mp4":       ".mp4",
-		"video/webm":      ".webm",
-		"video/ogg":       ".ogv",
-		"video/quicktime": ".mov",
-		"video/x-msvideo": ".avi",
-	}
 }
 
+// FileInfo 文件元信息，由 Filer.Info 生成，可直接序列化为 JSON（Body 不参与序列化）
 type FileInfo struct {
-	Path  null.String    // Path
-	Type  null.String    // Type
-	Name  null.String    // Name with extension
-	Title null.String    // Name without extension
-	Uri   null.String    // URI
-	Size  null.Int       // Size
-	Ext   null.String    // Extension
-	Body  *io.ReadCloser // Content
+	Path  null.String    `json:"path"`  // Path
+	Type  null.String    `json:"type"`  // Type
+	Name  null.String    `json:"name"`  // Name with extension
+	Title null.String    `json:"title"` // Name without extension
+	Uri   null.String    `json:"uri"`   // URI
+	Size  null.Int       `json:"size"`  // Size
+	Ext   null.String    `json:"ext"`   // Extension
+	Body  *io.ReadCloser `json:"-"`     // Content
 }
 
 type Filer struct {
+	ctx         context.Context
 	path        string
-	typ         string
+	typ         SourceType
 	name        string
 	size        int64
 	possibleExt string
@@ -128,6 +86,10 @@ type Filer struct {
 	writeCloser io.WriteCloser
 	error       error
 	imager      *Imager
+	opts        options
+
+	hashes          Hashes     // 已计算的摘要，见 Hash
+	headerChecksums []Checksum // 网络响应头中的摘要，见 WithVerifyHeaderChecksum
 }
 
 type ReadSeekCloser struct {
@@ -137,9 +99,26 @@ type ReadSeekCloser struct {
 // Close 关闭 ReadSeekCloser
 func (r *ReadSeekCloser) Close() error { return nil }
 
-// NewFiler 创建 Filer 实例
-func NewFiler() *Filer {
-	return &Filer{}
+// contextReader 在每次 Read 前检查 ctx，使读取在 ctx 取消或超时后尽快停止。
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// NewFiler 创建 Filer 实例，可通过 Option 定制网络请求等行为
+func NewFiler(opts ...Option) *Filer {
+	f := &Filer{}
+	for _, opt := range opts {
+		opt(&f.opts)
+	}
+	return f
 }
 
 // plausibleFilenameExt 排除小数等被 filepath 误判为扩展名的情况（如 "version 1.2" → ".2"）。
@@ -191,9 +170,85 @@ func stringLooksLikeFilePath(s string) bool {
 }
 
 // Open 打开需要处理的文件
-// 支持的文件格式为 network, base64, local file, text-content, os.File, FormFile
+// 支持的文件格式为 network, base64, local file, text-content, os.File, FormFile, fs.File,
+// 以及通用的 io.Reader / io.ReadSeeker / 带 Size() 的 io.ReaderAt
 func (f *Filer) Open(file any) error {
-	// Reset file attributes before open
+	return f.OpenContext(context.Background(), file)
+}
+
+// OpenContext 与 Open 相同，但使用 ctx 控制网络请求以及后续 Body、SaveTo、Imager 等读取操作；
+// ctx 取消或超时后，进行中的下载与读取会尽快返回 ctx.Err()。
+func (f *Filer) OpenContext(ctx context.Context, file any) error {
+	return f.openWith(ctx, func() error { return f.open(ctx, file) })
+}
+
+// OpenURL 按网络地址打开，rawURL 必须是带主机名的 http/https URL
+func (f *Filer) OpenURL(rawURL string) error {
+	return f.OpenURLContext(context.Background(), rawURL)
+}
+
+// OpenURLContext 与 OpenURL 相同，使用 ctx 控制网络请求及后续读取
+func (f *Filer) OpenURLContext(ctx context.Context, rawURL string) error {
+	return f.openWith(ctx, func() error {
+		u, ok := parseNetworkURL(rawURL)
+		if !ok {
+			return fmt.Errorf("%w, not an http(s) URL", ErrSourceMismatch)
+		}
+		return f.openNetwork(ctx, rawURL, u)
+	})
+}
+
+// OpenPath 按本地文件路径打开，文件不存在等错误会直接返回，不会回退为文本
+func (f *Filer) OpenPath(path string) error {
+	return f.openWith(context.Background(), func() error { return f.openLocalFile(path) })
+}
+
+// OpenText 将字符串作为文本内容打开，不做任何猜测
+func (f *Filer) OpenText(text string) error {
+	return f.openWith(context.Background(), func() error {
+		f.openText(text)
+		return nil
+	})
+}
+
+// OpenDataURI 按 base64 Data URI（data:<mime>;base64,...）打开，格式不符时返回错误
+func (f *Filer) OpenDataURI(dataURI string) error {
+	return f.openWith(context.Background(), func() error {
+		if !rxDataURI.MatchString(dataURI) {
+			return fmt.Errorf("%w, not a base64 data URI", ErrSourceMismatch)
+		}
+		return f.openDataURI(dataURI)
+	})
+}
+
+// openWith 重置文件属性后调用 fn 打开数据源，并统一执行大小限制与错误包装
+func (f *Filer) openWith(ctx context.Context, fn func() error) error {
+	if ctx == nil {
+		return &FilerError{Op: "open", Err: ErrNilContext}
+	}
+	f.reset(ctx)
+	err := fn()
+	if err == nil {
+		if err = f.checkMaxSize(); err == nil && f.opts.policy != nil {
+			err = f.validate(f.opts.policy)
+		}
+		if err == nil {
+			err = f.checkStrictExt(f.declaredExt())
+		}
+		if err != nil {
+			_ = f.Close()
+			f.readCloser = nil
+		}
+	}
+	if err != nil {
+		return f.wrapError("open", f.sourcePath(), err)
+	}
+	return nil
+}
+
+// reset 重置文件属性，通过 Option 设置的选项保留
+func (f *Filer) reset(ctx context.Context) {
+	f.ctx = ctx
 	f.path = ""
 	f.typ = ""
 	f.name = ""
@@ -205,98 +260,147 @@ func (f *Filer) Open(file any) error {
 	f.writeCloser = nil
 	f.error = nil
 	f.imager = nil
+	f.hashes = nil
+	f.headerChecksums = nil
+}
+
+// parseNetworkURL 判断 s 是否为带主机名的 http/https URL
+func parseNetworkURL(s string) (*url.URL, bool) {
+	u, err := url.Parse(s)
+	if err != nil || !slices.Contains([]string{"http", "https"}, u.Scheme) || u.Host == "" {
+		return nil, false
+	}
+	return u, true
+}
 
+// openNetwork 打开网络文件
+func (f *Filer) openNetwork(ctx context.Context, s string, u *url.URL) error {
+	f.typ = SourceNetwork
+	f.path = s
+	resp, err := f.fetch(ctx, s)
+	if err != nil {
+		return err
+	}
+	f.name, f.ext = networkFileName(f.mimeRegistry(), u, resp.Header)
+	f.possibleExt = path.Ext(f.name)
+	f.readCloser = resp.Body
+	f.size = resp.ContentLength
+	if f.opts.headerChecksum && !resp.Uncompressed {
+		f.headerChecksums = headerChecksums(resp.Header)
+	}
+	return nil
+}
+
+// openDataURI 处理 base64 编码的文件
+func (f *Filer) openDataURI(s string) error {
+	f.typ = SourceBase64
+	f.path = s
+	parts := strings.Split(s, ";")
+	if len(parts) != 2 {
+		return ErrInvalidBase64
+	}
+
+	payload := strings.TrimPrefix(parts[1], "base64,")
+	if limit := f.opts.maxSize; limit > 0 {
+		// 解码前按长度拒绝，避免超大 Data URI 被整体解码进内存
+		if size := base64DecodedLen(payload); size > limit {
+			return &SizeLimitError{Limit: limit, Size: size}
+		}
+	}
+	decodedData, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return fmt.Errorf("%w, %w", ErrInvalidBase64, err)
+	}
+	f.size = int64(len(decodedData))
+	// 使用 ReadSeekCloser 保留 Seeker 能力，便于 IsImage/Imager/Size 等。
+	f.readCloser = &ReadSeekCloser{bytes.NewReader(decodedData)}
+	f.ext = detectFileExt(f.mimeRegistry(), decodedData)
+	return nil
+}
+
+// openLocalFile 打开本地文件
+func (f *Filer) openLocalFile(s string) error {
+	f.typ = SourceLocalFile
+	f.path = s
+	readCloser, err := os.Open(s)
+	if err != nil {
+		return err
+	}
+	// 目录可以被 os.Open 打开，但读取时才会出错
+	info, err := readCloser.Stat()
+	if err != nil {
+		_ = readCloser.Close()
+		return err
+	}
+	if info.IsDir() {
+		_ = readCloser.Close()
+		return fmt.Errorf("%w, %s is a directory", ErrUnsupportedSource, s)
+	}
+	f.possibleExt = filepath.Ext(s)
+	f.readCloser = readCloser
+	f.name = filepath.Base(s)
+	return nil
+}
+
+// openText 将字符串作为文本内容
+func (f *Filer) openText(s string) {
+	f.typ = SourceText
+	f.path = s
+	f.size = int64(len(s))
+	f.readCloser = &ReadSeekCloser{bytes.NewReader([]byte(s))}
+}
+
+// open 按数据源类型初始化 Filer
+func (f *Filer) open(ctx context.Context, file any) error {
 	switch s := file.(type) {
 	case string:
-		f.path = s
-		var u *url.URL
-		u, err := url.Parse(f.path)
-		if err == nil && slices.Contains([]string{"http", "https"}, u.Scheme) && u.Host != "" {
-			f.typ = network
-			var resp *http.Response
-			resp, err = defaultHTTPClient.Get(s)
-			if err != nil {
-				return fmt.Errorf("filer: %w", err)
-			}
-			if resp.StatusCode != http.StatusOK {
-				_ = resp.Body.Close()
-				return fmt.Errorf("filer: response status %s", resp.Status)
-			}
-			f.name = filepath.Base(u.Path)
-			f.readCloser = resp.Body
-			f.size = resp.ContentLength
-		} else if rxDataURI.MatchString(s) {
-			// 处理 base64 编码的文件
-			f.typ = base64Type
-			parts := strings.Split(s, ";")
-			if len(parts) != 2 {
-				return errors.New("filer: invalid base64 format")
-			}
-
-			var decodedData []byte
-			decodedData, err = base64.StdEncoding.DecodeString(strings.TrimPrefix(parts[1], "base64,"))
-			if err != nil {
-				return fmt.Errorf("filer: %w", err)
-			}
-			f.size = int64(len(decodedData))
-			// 使用 ReadSeekCloser 保留 Seeker 能力，便于 IsImage/Imager/Size 等。
-			f.readCloser = &ReadSeekCloser{bytes.NewReader(decodedData)}
-			f.ext = detectFileExt(decodedData)
-		} else {
-			// 判断是普通文本还是文件路径（路径形态与正文无法严格区分，见 stringLooksLikeFilePath 注释）
-			if stringLooksLikeFilePath(s) {
-				readCloser, err := os.Open(f.path)
-				if err != nil {
-					if errors.Is(err, os.ErrNotExist) {
-						f.typ = textContent
-						f.size = int64(len(s))
-						f.readCloser = &ReadSeekCloser{bytes.NewReader([]byte(s))}
-					} else {
-						return fmt.Errorf("filer: %w", err)
-					}
-				} else {
-					f.typ = localFilePath
-					f.possibleExt = filepath.Ext(s)
-					f.readCloser = readCloser
-					f.name = filepath.Base(f.path)
-				}
-			} else {
-				f.typ = textContent
-				f.size = int64(len(s))
-				f.readCloser = &ReadSeekCloser{bytes.NewReader([]byte(s))}
+		if u, ok := parseNetworkURL(s); ok {
+			return f.openNetwork(ctx, s, u)
+		}
+		if rxDataURI.MatchString(s) {
+			return f.openDataURI(s)
+		}
+		// 判断是普通文本还是文件路径（路径形态与正文无法严格区分，见 stringLooksLikeFilePath 注释）
+		if stringLooksLikeFilePath(s) {
+			if err := f.openLocalFile(s); !errors.Is(err, os.ErrNotExist) {
+				return err
 			}
 		}
+		f.openText(s)
 	case []byte:
-		f.typ = fileBytes
+		f.typ = SourceBytes
 		f.size = int64(len(s))
 		f.readCloser = &ReadSeekCloser{bytes.NewReader(s)}
 	case *os.File:
-		f.typ = osFile
+		f.typ = SourceOSFile
 		f.path = s.Name()
 		f.possibleExt = filepath.Ext(s.Name())
 		f.readCloser = s
+	case fs.File:
+		// 须在 multipart.File 之前：embed.FS 等的文件同样实现了 ReadAt/Seek/Close
+		return f.openFSFile(s)
 	case multipart.File:
-		f.typ = formFile
+		f.typ = SourceFormFile
 		f.readCloser = s
 	case FormFile:
-		f.typ = formFile
+		f.typ = SourceFormFile
 		fn := strings.TrimSpace(s.Header.Filename)
 		f.name = fn
 		f.ext = path.Ext(fn)
 		f.size = s.Header.Size
 		f.readCloser = s.File
 	case *FormFile:
-		f.typ = formFile
+		f.typ = SourceFormFile
 		fn := strings.TrimSpace(s.Header.Filename)
 		f.name = fn
 		f.ext = path.Ext(fn)
 		f.size = s.Header.Size
 		f.readCloser = s.File
 	case *multipart.FileHeader:
-		f.typ = formFile
+		f.typ = SourceFormFile
 		f1, err := s.Open()
 		if err != nil {
-			return fmt.Errorf("filer: %w", err)
+			return err
 		}
 		fn := strings.TrimSpace(s.Filename)
 		f.name = fn
@@ -304,26 +408,84 @@ func (f *Filer) Open(file any) error {
 		f.ext = filepath.Ext(fn)
 		f.size = s.Size
 		f.readCloser = f1
+	case io.Reader:
+		return f.openReader(s)
+	case sizedReaderAt:
+		return f.openReaderAt(s)
 	case nil:
-		return errors.New("filer: open data is nil")
+		return ErrNilSource
 	default:
-		return fmt.Errorf("filer: unsupported file format %T", s)
+		return fmt.Errorf("%w %T", ErrUnsupportedSource, s)
 	}
-
 	return nil
 }
 
+// context 返回 OpenContext 传入的 ctx，未打开时为 context.Background()
+func (f *Filer) context() context.Context {
+	if f.ctx == nil {
+		return context.Background()
+	}
+	return f.ctx
+}
+
+// reader 返回受 ctx 控制的读取流
+func (f *Filer) reader() io.Reader {
+	return &contextReader{ctx: f.context(), r: f.readCloser}
+}
+
+// contentDispositionFilename 从 Content-Disposition 中解析文件名，支持 RFC 5987 的 filename* UTF-8 编码形式
+// （mime.ParseMediaType 会优先采用解码后的 filename*）；格式不规范时退回宽松匹配。
+func contentDispositionFilename(cd string) string {
+	if cd == "" {
+		return ""
+	}
+	name := ""
+	if _, params, err := mime.ParseMediaType(cd); err == nil {
+		name = params["filename"]
+	} else if m := rxDispositionFilename.FindStringSubmatch(cd); m != nil {
+		name = m[1]
+	}
+	// 只保留最后一段，避免 "../../etc/passwd" 之类的路径穿越
+	name = path.Base(strings.ReplaceAll(strings.TrimSpace(name), `\`, "/"))
+	if name == "." || name == "/" || name == ".." {
+		return ""
+	}
+	return name
+}
+
+// networkFileName 根据响应头与 URL 推断网络文件的文件名及扩展名提示。
+// 文件名优先取 Content-Disposition，其次取 URL 路径的最后一段；扩展名提示取自 Content-Type，
+// 文件名缺少扩展名时（如 /download?id=42）会补上该提示。
+func networkFileName(registry *MIMERegistry, u *url.URL, header http.Header) (name, ext string) {
+	if ct := header.Get("Content-Type"); ct != "" {
+		ext, _ = registry.Ext(ct)
+	}
+	name = contentDispositionFilename(header.Get("Content-Disposition"))
+	if name == "" {
+		name = path.Base(u.Path)
+		if name == "." || name == "/" {
+			name = ""
+		}
+	}
+	if name != "" && ext != "" {
+		if e := path.Ext(name); e == "" || !plausibleFilenameExt(e) {
+			name += ext
+		}
+	}
+	return name, ext
+}
+
 // ensureSeekable 将 readCloser 转成可 Seek 的内存流（必要时读入全部字节）。
 // 用于图片嗅探/解码等需要回退或重复读取的场景（如 IsImage / Imager）。
 func (f *Filer) ensureSeekable() error {
 	if f.readCloser == nil {
-		return errors.New("filer: no read file")
+		return ErrNotOpened
 	}
 	if _, ok := f.readCloser.(io.Seeker); ok {
 		return nil
 	}
 
-	b, err := io.ReadAll(f.readCloser)
+	b, err := io.ReadAll(f.reader())
 	if err != nil {
 		return err
 	}
@@ -331,12 +493,17 @@ func (f *Filer) ensureSeekable() error {
 	_ = f.readCloser.Close()
 	f.readCloser = &ReadSeekCloser{bytes.NewReader(b)}
 	// 对网络源，Content-Length 可能为 -1；缓冲后可得真实 size。
-	if f.typ == network || f.typ == base64Type || f.typ == textContent || f.size <= 0 {
+	if f.typ == SourceNetwork || f.typ == SourceBase64 || f.typ == SourceText || f.size <= 0 {
 		f.size = int64(len(b))
 	}
 	return nil
 }
 
+// SourceType 返回数据源类型，可据此判断 Open(string) 的输入被识别为 URL、Data URI、本地文件还是文本
+func (f *Filer) SourceType() SourceType {
+	return f.typ
+}
+
 // Name 文件名（带扩展名）
 func (f *Filer) Name() string {
 	return f.name
@@ -364,33 +531,24 @@ func mimeBaseType(mediaType string) string {
 	return mediaType
 }
 
-// lookupCommonMimeExt 查找 mime 的常见扩展名
-func lookupCommonMimeExt(mediaType string) (string, bool) {
-	if ext, ok := commonMimeTypeExt[mediaType]; ok {
-		return ext, true
-	}
-	if base := mimeBaseType(mediaType); base != mediaType {
-		if ext, ok := commonMimeTypeExt[base]; ok {
-			return ext, true
-		}
-	}
-	return "", false
-}
-
 // detectFileExt 检测文件扩展名
-func detectFileExt(data []byte, suggestExtensions ...string) string {
-	mimeType := http.DetectContentType(data)
+func detectFileExt(registry *MIMERegistry, data []byte, suggestExtensions ...string) string {
+	return sniffedExt(registry, sniffContent(data, nil, -1), suggestExtensions...)
+}
 
+// sniffedExt 根据内容识别结果选择扩展名：建议的扩展名与结果一致时沿用，其次使用映射表中的首选扩展名、
+// 签名库给出的扩展名，最后查标准库
+func sniffedExt(registry *MIMERegistry, r sniffResult, suggestExtensions ...string) string {
 	suggestExt := ""
 	if len(suggestExtensions) != 0 {
 		suggestExt = strings.ToLower(suggestExtensions[0])
 	}
-
-	// 优先查手动表（支持 text/plain; charset=utf-8 等形式；无 suggestExt 时直接采用表内映射）
-	if ext, ok := lookupCommonMimeExt(mimeType); ok {
-		if suggestExt == "" || ext == suggestExt {
-			return ext
-		}
+	if registry.compatibleExt(r, suggestExt) {
+		return suggestExt
+	}
+	mimeType := r.mimeType
+	if mimeType == "" {
+		return ""
 	}
 	extensions, _ := mime.ExtensionsByType(mimeType)
 	if len(extensions) == 0 {
@@ -398,13 +556,19 @@ func detectFileExt(data []byte, suggestExtensions ...string) string {
 			extensions, _ = mime.ExtensionsByType(base)
 		}
 	}
-	if len(extensions) == 0 {
-		return ""
-	}
-
 	if suggestExt != "" && slices.Contains(extensions, suggestExt) {
 		return suggestExt
 	}
+	// 映射表支持 text/plain; charset=utf-8 等形式
+	if ext, ok := registry.Ext(mimeType); ok {
+		return ext
+	}
+	if r.ext != "" {
+		return r.ext
+	}
+	if len(extensions) == 0 {
+		return ""
+	}
 
 	slices.SortStableFunc(extensions, func(a, b string) int {
 		return len(b) - len(a)
@@ -433,37 +597,40 @@ func (f *Filer) Ext() string {
 		return strings.ToLower(f.ext)
 	}
 
-	if seeker, ok := f.readCloser.(io.Seeker); ok {
-		// 保存当前位置
-		pos, err := seeker.Seek(0, io.SeekCurrent)
-		if err == nil {
-			var buf [512]byte
-			n, err2 := f.readCloser.Read(buf[:])
-			// 恢复当前位置
-			_, _ = seeker.Seek(pos, io.SeekStart)
-			if err2 == nil || err2 == io.EOF {
-				// 流在 EOF 时 n==0，勿对空片做嗅探，否则易误判成文本等，应回退到 path 上的扩展名
-				if n > 0 {
-					ext := detectFileExt(buf[:n], f.possibleExt)
-					if ext != "" {
-						return strings.ToLower(ext)
-					}
-				}
-			}
+	// 始终从头按内容识别（流可能已被读到 EOF，例如 SaveTo 后），读取位置保持不变
+	if r, err := f.sniff(); err == nil && r.mimeType != "" {
+		if ext := sniffedExt(f.mimeRegistry(), r, f.possibleExt); ext != "" {
+			return strings.ToLower(ext)
 		}
 	}
 
+	// 无法嗅探时依次回退到文件名、响应头或表单给出的扩展名，最后才取路径上的扩展名
+	if f.possibleExt != "" {
+		return strings.ToLower(f.possibleExt)
+	}
+	if f.ext != "" {
+		return strings.ToLower(f.ext)
+	}
 	return strings.ToLower(filepath.Ext(f.path))
 }
 
 // Size 文件大小
 func (f *Filer) Size() (int64, error) {
+	size, err := f.sizeOf()
+	if err != nil {
+		return 0, f.wrapError("size", f.sourcePath(), err)
+	}
+	return size, nil
+}
+
+// sizeOf 计算文件大小，可 Seek 的流会被恢复到起始位置
+func (f *Filer) sizeOf() (int64, error) {
 	if f.readCloser == nil {
-		return 0, errors.New("filer: no read file")
+		return 0, ErrNotOpened
 	}
 
 	switch f.typ {
-	case network, base64Type, textContent:
+	case SourceNetwork, SourceBase64, SourceText:
 		return f.size, nil
 
 	default:
@@ -478,7 +645,11 @@ func (f *Filer) Size() (int64, error) {
 			}
 			return size, nil
 		}
-		return 0, errors.New("filer: readCloser is not a seeker")
+		if f.typ == SourceReader || f.typ == SourceFSFile {
+			// 不可 Seek 的流：返回 Stat 得到的大小，未知时为 -1
+			return f.size, nil
+		}
+		return 0, ErrNotSeekable
 	}
 }
 
@@ -539,87 +710,181 @@ func (f *Filer) seekStart() error {
 // Body 获取文件内容
 func (f *Filer) Body() ([]byte, error) {
 	if f.readCloser == nil {
-		return nil, errors.New("filer: no read content")
+		return nil, f.wrapError("read", "", ErrNotOpened)
 	}
 
 	err := f.seekStart()
 	if err != nil {
-		return nil, err
+		return nil, f.wrapError("read", f.sourcePath(), err)
 	}
 
-	return io.ReadAll(f.readCloser)
+	r, finish, err := f.hashingReader()
+	if err != nil {
+		return nil, f.wrapError("read", f.sourcePath(), err)
+	}
+	b, err := io.ReadAll(r)
+	if err == nil {
+		err = finish()
+	}
+	if err != nil {
+		return nil, f.wrapError("read", f.sourcePath(), err)
+	}
+	return b, nil
 }
 
 // SaveTo 保存文件到指定位置
 // 如果只指定路径（以 "/" 或者 "\" 结尾），不指定文件名称，将使用原文件名作为保存后的文件名
+// 内容先写入同目录下的临时文件并 fsync，成功后再重命名为目标文件（原子替换）
 func (f *Filer) SaveTo(filename string) (string, error) {
+	savedPath, err := f.saveTo(filename)
+	if err != nil {
+		if savedPath == "" {
+			savedPath = strings.TrimSpace(filename)
+		}
+		return "", f.wrapError("save", savedPath, err)
+	}
+	return savedPath, nil
+}
+
+// saveTo 执行 SaveTo，出错时 savedPath 为已确定的目标路径（可能为空）
+func (f *Filer) saveTo(filename string) (savedPath string, err error) {
 	if f.readCloser == nil {
-		return "", errors.New("filer: no read file")
+		return "", ErrNotOpened
 	}
 
 	filename = strings.TrimSpace(filename)
 	if filename == "" {
-		return "", errors.New("filer: filename is can't empty")
+		return "", ErrEmptyPath
 	}
 	// Windows 风格路径在 Unix 上 "\" 不是分隔符，会导致 ".\\tmp/..." 等异常路径；先统一成 "/" 再交给 FromSlash。
 	filename = filepath.FromSlash(strings.ReplaceAll(filename, `\`, `/`))
 
 	if strings.HasSuffix(filename, "/") || strings.HasSuffix(filename, "\\") {
 		// Append file name
-		name := f.Name()
-		if name == "" {
-			name = fmt.Sprintf("%d%s", time.Now().Nanosecond(), f.Ext())
+		name, err := f.targetName()
+		if err != nil {
+			return "", err
 		}
-		filename += string(os.PathSeparator) + name
+		filename += string(os.PathSeparator) + filepath.FromSlash(name)
 	}
 	filename = filepath.Clean(filename)
-	uri := ""
-	if !filepath.IsAbs(filename) {
-		uri = strings.ReplaceAll(filename, "\\", "/")
-		if strings.HasPrefix(uri, ".") {
-			uri = uri[1:]
-		}
-		if !strings.HasPrefix(uri, "/") {
-			uri = "/" + uri
-		}
-	}
-	f.uri = uri
 	filename = filepath.FromSlash(filename)
 	dir := filepath.Dir(filename)
 	// Creates dir and subdirectories if they do not exist
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return "", fmt.Errorf("filer: make %s directory failed, %w", dir, err)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return filename, fmt.Errorf("make %s directory failed, %w", dir, err)
 	}
 	// Creates file（勿用 dir=="."：当前目录下的 out.txt 等会使 Dir 为 "." 导致误判）
 	if strings.HasSuffix(filename, dir) {
 		// 未提供文件名，则使用随机文件名
-		filename = filepath.Join(filename, fmt.Sprintf("%d%s", time.Now().Nanosecond(), f.Ext()))
+		name, err := newUUIDv7(time.Now())
+		if err != nil {
+			return filename, err
+		}
+		filename = filepath.Join(filename, name+f.Ext())
 	}
-	file, err := os.Create(filename)
-	if err != nil {
-		return "", fmt.Errorf("filer: create %s file failed, %w", filename, err)
+	if err = f.validateTarget(filename); err != nil {
+		return filename, err
+	}
+	if err = f.checkStrictTarget(filename); err != nil {
+		return filename, err
+	}
+	// 目标不在 URLBuilder 根目录内时不写入；改名后的文件位于同一目录，结果不变
+	if _, err = f.fileURI(filename); err != nil {
+		return filename, err
 	}
-	defer func() {
-		if err1 := file.Close(); err == nil && err1 != nil {
-			err = fmt.Errorf("filer: close %s file failed, %w", filename, err1)
-		}
-	}()
-
 	if err = f.seekStart(); err != nil {
-		return "", fmt.Errorf("filer: seek %s file data failed, %w", filename, err)
+		return filename, fmt.Errorf("seek %s file data failed, %w", filename, err)
 	}
-	_, err = io.Copy(file, f.readCloser)
+	quota, err := f.checkQuota(filename)
 	if err != nil {
-		return "", fmt.Errorf("filer: write %s file data failed, %w", filename, err)
+		return filename, err
 	}
+	// 先写临时文件再重命名，失败时不会在目标路径留下半截文件；目标已存在时按 ConflictPolicy 处理
+	r, finish, err := f.hashingReader()
+	if err != nil {
+		return filename, err
+	}
+	var qw *quotaWriter
+	filename, written, err := writeFileWithPolicy(filename, func(w io.Writer) error {
+		if quota != nil {
+			// 大小未知或不准确时，在写入过程中检查配额
+			qw = &quotaWriter{w: w, st: quota}
+			w = qw
+		}
+		if _, err := io.Copy(w, r); err != nil {
+			return fmt.Errorf("write %s file data failed, %w", filename, err)
+		}
+		// 校验失败时临时文件会被删除，目标路径保持原样
+		return finish()
+	}, f.opts.conflict, f.opts.syncDir)
+	if err != nil {
+		return filename, err
+	}
+	if written && qw != nil {
+		if err = qw.commit(); err != nil {
+			return filename, err
+		}
+	}
+	f.uri, _ = f.fileURI(filename)
 	return filename, nil
 }
 
+// pathURI 将相对路径转换为以 "/" 开头的 URI（去掉前导 "."），绝对路径返回空字符串
+func pathURI(filename string) string {
+	if filepath.IsAbs(filename) {
+		return ""
+	}
+	uri := strings.ReplaceAll(filename, "\\", "/")
+	if strings.HasPrefix(uri, ".") {
+		uri = uri[1:]
+	}
+	if !strings.HasPrefix(uri, "/") {
+		uri = "/" + uri
+	}
+	return uri
+}
+
 // Uri 获取文件 URI
 func (f *Filer) Uri() string {
 	return f.uri
 }
 
+// Info 汇总文件元信息。
+// 大小未知的网络流会先缓冲为内存流再计算大小（受 WithMaxSize 约束），之后的 Body、SaveTo 复用该缓冲；
+// 文本与 Data URI 的 Path 为空，避免把内容当作路径输出。Body 指向已回到起始位置的底层流，由 Filer 负责关闭。
+func (f *Filer) Info() (FileInfo, error) {
+	if f.readCloser == nil {
+		return FileInfo{}, f.wrapError("info", "", ErrNotOpened)
+	}
+	size, err := f.sizeOf()
+	if err != nil || size < 0 {
+		if err = f.ensureSeekable(); err != nil {
+			return FileInfo{}, f.wrapError("info", f.sourcePath(), err)
+		}
+		if size, err = f.sizeOf(); err != nil {
+			return FileInfo{}, f.wrapError("info", f.sourcePath(), err)
+		}
+	}
+	if err = f.seekStart(); err != nil {
+		return FileInfo{}, f.wrapError("info", f.sourcePath(), err)
+	}
+
+	path := f.sourcePath()
+	ext := f.Ext()
+	body := f.readCloser
+	return FileInfo{
+		Path:  null.NewString(path, path != ""),
+		Type:  null.NewString(string(f.typ), f.typ != ""),
+		Name:  null.NewString(f.name, f.name != ""),
+		Title: null.NewString(f.Title(), f.Title() != ""),
+		Uri:   null.NewString(f.uri, f.uri != ""),
+		Size:  null.IntFrom(size),
+		Ext:   null.NewString(ext, ext != ""),
+		Body:  &body,
+	}, nil
+}
+
 // Close 关闭文件流
 func (f *Filer) Close() error {
 	if f.readCloser == nil {
@@ -631,18 +896,18 @@ func (f *Filer) Close() error {
 // Imager 获取 Imager 实例
 func (f *Filer) Imager() (*Imager, error) {
 	if f.readCloser == nil {
-		return nil, errors.New("filer: no read file")
+		return nil, f.wrapError("imager", "", ErrNotOpened)
 	}
 	if err := f.ensureSeekable(); err != nil {
-		return nil, fmt.Errorf("filer: %w", err)
+		return nil, f.wrapError("imager", f.sourcePath(), err)
 	}
 	if !f.IsImage() {
-		return nil, errors.New("filer: not an image")
+		return nil, f.wrapError("imager", f.sourcePath(), ErrNotImage)
 	}
 
 	imager, err := newImager(f)
 	if err != nil {
-		return nil, err
+		return nil, f.wrapError("imager", f.sourcePath(), err)
 	}
 	f.imager = imager
 	return imager, nil
@@ -2,9 +2,13 @@ package filer_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"image"
 	"image/jpeg"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
@@ -12,6 +16,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/hiscaler/filer-go"
 	"github.com/stretchr/testify/assert"
@@ -365,3 +370,192 @@ func TestOpen_string_plainFilenameWithExtInTemp(t *testing.T) {
 	assert.NoError(t, ff.Open(path))
 	assert.Equal(t, "photo.jpg", ff.Name())
 }
+
+// TestOpenContext_CanceledBeforeOpen 验证 ctx 已取消时网络源直接返回错误。
+func TestOpenContext_CanceledBeforeOpen(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	f := filer.NewFiler()
+	defer func() { _ = f.Close() }()
+	err := f.OpenContext(ctx, srv.URL+"/a.txt")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+// TestOpenContext_CancelDuringSaveTo 验证下载中途取消 ctx 时 SaveTo 尽快返回。
+func TestOpenContext_CancelDuringSaveTo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(bytes.Repeat([]byte("a"), 1024))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f := filer.NewFiler()
+	defer func() { _ = f.Close() }()
+	assert.NoError(t, f.OpenContext(ctx, srv.URL+"/a.txt"))
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err := f.SaveTo(filepath.Join(t.TempDir(), "a.txt"))
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+// TestOpenContext_BodyAfterCancel 验证 ctx 取消后 Body 不再读取内存流。
+func TestOpenContext_BodyAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := filer.NewFiler()
+	defer func() { _ = f.Close() }()
+	assert.NoError(t, f.OpenContext(ctx, []byte("hello")))
+	cancel()
+	_, err := f.Body()
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestOpen_HTTPURL_ContentDisposition 验证网络源优先使用 Content-Disposition 中的文件名（含 filename*）。
+func TestOpen_HTTPURL_ContentDisposition(t *testing.T) {
+	tests := []struct {
+		name        string
+		disposition string
+		contentType string
+		wantName    string
+		wantExt     string
+	}{
+		{"quoted", `attachment; filename="report.pdf"`, "application/pdf", "report.pdf", ".pdf"},
+		{"rfc5987", `attachment; filename="a.txt"; filename*=UTF-8''%E6%8A%A5%E5%91%8A.txt`, "text/plain", "报告.txt", ".txt"},
+		{"traversal", `attachment; filename="../../etc/passwd.txt"`, "text/plain", "passwd.txt", ".txt"},
+		{"content-type only", "", "image/png", "download.png", ".png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.disposition != "" {
+					w.Header().Set("Content-Disposition", tt.disposition)
+				}
+				w.Header().Set("Content-Type", tt.contentType)
+				_, _ = w.Write([]byte("content"))
+			}))
+			defer srv.Close()
+
+			f := filer.NewFiler()
+			defer func() { _ = f.Close() }()
+			assert.NoError(t, f.Open(srv.URL+"/download?id=42"))
+			assert.Equal(t, tt.wantName, f.Name())
+			assert.Equal(t, tt.wantExt, f.Ext())
+
+			dir := t.TempDir()
+			saved, err := f.SaveTo(dir + "/")
+			assert.NoError(t, err)
+			assert.Equal(t, filepath.Join(dir, tt.wantName), saved)
+		})
+	}
+}
+
+// TestFiler_Info 验证 Info 汇总元信息并可序列化为 JSON。
+func TestFiler_Info(t *testing.T) {
+	f := filer.NewFiler()
+	defer func() { _ = f.Close() }()
+	assert.NoError(t, f.Open("./tests/test.jpg"))
+	_, err := f.SaveTo(filepath.Join("tmp", "info.jpg"))
+	assert.NoError(t, err)
+
+	info, err := f.Info()
+	assert.NoError(t, err)
+	assert.Equal(t, "./tests/test.jpg", info.Path.String)
+	assert.Equal(t, "local-file", info.Type.String)
+	assert.Equal(t, "test.jpg", info.Name.String)
+	assert.Equal(t, "test", info.Title.String)
+	assert.Equal(t, "/tmp/info.jpg", info.Uri.String)
+	assert.Equal(t, int64(11876), info.Size.Int64)
+	assert.Equal(t, ".jpg", info.Ext.String)
+
+	b, err := io.ReadAll(*info.Body)
+	assert.NoError(t, err)
+	assert.Len(t, b, 11876)
+
+	data, err := json.Marshal(info)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"path":"./tests/test.jpg","type":"local-file","name":"test.jpg","title":"test","uri":"/tmp/info.jpg","size":11876,"ext":".jpg"}`, string(data))
+}
+
+// TestFiler_Info_UnknownSizeAndText 验证大小未知的网络流会被缓冲计算大小，文本源不输出 Path。
+func TestFiler_Info_UnknownSizeAndText(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello "))
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte("world"))
+	}))
+	defer srv.Close()
+
+	f := filer.NewFiler()
+	defer func() { _ = f.Close() }()
+	assert.NoError(t, f.Open(srv.URL+"/hello.txt"))
+	info, err := f.Info()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), info.Size.Int64)
+	b, err := f.Body()
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", string(b))
+
+	assert.NoError(t, f.Open("some text"))
+	info, err = f.Info()
+	assert.NoError(t, err)
+	assert.False(t, info.Path.Valid)
+	assert.False(t, info.Name.Valid)
+	assert.Equal(t, "text-content", info.Type.String)
+}
+
+// TestFiler_SourceType 验证 Open(string) 的识别结果可通过 SourceType 获取。
+func TestFiler_SourceType(t *testing.T) {
+	tests := []struct {
+		name string
+		src  any
+		want filer.SourceType
+	}{
+		{"local file", "./tests/test.jpg", filer.SourceLocalFile},
+		{"missing path falls back to text", "./no-such-dir/a.jpg", filer.SourceText},
+		{"text", "hello", filer.SourceText},
+		{"data uri", "data:text/plain;base64," + base64.StdEncoding.EncodeToString([]byte("hi")), filer.SourceBase64},
+		{"bytes", []byte("hi"), filer.SourceBytes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := filer.NewFiler()
+			defer func() { _ = f.Close() }()
+			assert.NoError(t, f.Open(tt.src))
+			assert.Equal(t, tt.want, f.SourceType())
+		})
+	}
+}
+
+// TestFiler_ExplicitOpeners 验证显式打开方法不做猜测，输入不符时直接报错。
+func TestFiler_ExplicitOpeners(t *testing.T) {
+	f := filer.NewFiler()
+	defer func() { _ = f.Close() }()
+
+	err := f.OpenPath("./no-such-dir/a.jpg")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.ErrorIs(t, f.OpenPath("./tests"), filer.ErrUnsupportedSource)
+	assert.ErrorIs(t, f.Open("./tests/"), filer.ErrUnsupportedSource)
+	assert.NoError(t, f.OpenPath("./tests/test.jpg"))
+	assert.Equal(t, filer.SourceLocalFile, f.SourceType())
+
+	assert.ErrorIs(t, f.OpenURL("./tests/test.jpg"), filer.ErrSourceMismatch)
+	assert.ErrorIs(t, f.OpenURL("ftp://example.com/a.jpg"), filer.ErrSourceMismatch)
+
+	assert.ErrorIs(t, f.OpenDataURI("hello"), filer.ErrSourceMismatch)
+	assert.NoError(t, f.OpenDataURI("data:text/plain;base64,"+base64.StdEncoding.EncodeToString([]byte("hi"))))
+	assert.Equal(t, filer.SourceBase64, f.SourceType())
+
+	assert.NoError(t, f.OpenText("./tests/test.jpg"))
+	assert.Equal(t, filer.SourceText, f.SourceType())
+	b, err := f.Body()
+	assert.NoError(t, err)
+	assert.Equal(t, "./tests/test.jpg", string(b))
+}
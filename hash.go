@@ -0,0 +1,300 @@
+package filer
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// HashAlgo 摘要算法
+type HashAlgo string
+
+// 支持的摘要算法
+const (
+	HashMD5    HashAlgo = "md5"
+	HashSHA1   HashAlgo = "sha1"
+	HashSHA256 HashAlgo = "sha256"
+	HashSHA512 HashAlgo = "sha512"
+	HashCRC32  HashAlgo = "crc32" // IEEE 多项式，大端序十六进制
+)
+
+// Hashes 各算法的十六进制（小写）摘要
+type Hashes map[HashAlgo]string
+
+// Checksum 期望的摘要值，Value 为十六进制（不区分大小写）
+type Checksum struct {
+	Algo  HashAlgo
+	Value string
+}
+
+// ChecksumError 内容摘要与期望值不一致
+type ChecksumError struct {
+	Algo     HashAlgo
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("filer: %s checksum mismatch, expected %s, got %s", e.Algo, e.Expected, e.Actual)
+}
+
+// Is 使 errors.Is(err, ErrChecksumMismatch) 成立
+func (e *ChecksumError) Is(target error) bool {
+	return target == ErrChecksumMismatch
+}
+
+// newHash 创建 algo 对应的 hash.Hash
+func newHash(algo HashAlgo) (hash.Hash, error) {
+	switch algo {
+	case HashMD5:
+		return md5.New(), nil
+	case HashSHA1:
+		return sha1.New(), nil
+	case HashSHA256:
+		return sha256.New(), nil
+	case HashSHA512:
+		return sha512.New(), nil
+	case HashCRC32:
+		return crc32.NewIEEE(), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedHash, algo)
+	}
+}
+
+// hashSet 一次写入同时计算多种摘要
+type hashSet struct {
+	algos  []HashAlgo
+	hashes []hash.Hash
+}
+
+func newHashSet(algos []HashAlgo) (*hashSet, error) {
+	s := &hashSet{}
+	for _, algo := range algos {
+		if slices.Contains(s.algos, algo) {
+			continue
+		}
+		h, err := newHash(algo)
+		if err != nil {
+			return nil, err
+		}
+		s.algos = append(s.algos, algo)
+		s.hashes = append(s.hashes, h)
+	}
+	return s, nil
+}
+
+func (s *hashSet) Write(p []byte) (int, error) {
+	for _, h := range s.hashes {
+		_, _ = h.Write(p)
+	}
+	return len(p), nil
+}
+
+func (s *hashSet) sums() Hashes {
+	sums := make(Hashes, len(s.algos))
+	for i, algo := range s.algos {
+		sums[algo] = hex.EncodeToString(s.hashes[i].Sum(nil))
+	}
+	return sums
+}
+
+// WithHashes 在 SaveTo、Body、SaveToStorage 等完整读取内容时顺带计算 algos 摘要，之后可通过 Hash 直接取得，无需再次读取
+func WithHashes(algos ...HashAlgo) Option {
+	return func(o *options) {
+		o.hashAlgos = append(o.hashAlgos, algos...)
+	}
+}
+
+// WithVerifyChecksum 在 SaveTo、Body、SaveToStorage 读完内容后校验摘要，不一致时返回 *ChecksumError，
+// SaveTo 不会留下目标文件，SaveToStorage 会使 Storage.Put 失败而不覆盖已有对象。value 为十六进制，可多次调用校验多种算法。
+func WithVerifyChecksum(algo HashAlgo, value string) Option {
+	return func(o *options) {
+		o.checksums = append(o.checksums, Checksum{Algo: algo, Value: value})
+	}
+}
+
+// WithVerifyHeaderChecksum 对网络源按响应头 Content-MD5、Digest / Repr-Digest（md5、sha、sha-256、sha-512）校验内容，
+// 规则与 WithVerifyChecksum 相同；响应被 Transport 自动解压时跳过（头部描述的是压缩后的内容）。
+func WithVerifyHeaderChecksum(enabled bool) Option {
+	return func(o *options) {
+		o.headerChecksum = enabled
+	}
+}
+
+// Hash 一次读取计算多种摘要（为空时为 HashSHA256），不会缓冲整个内容。
+// 若 SaveTo、Body 等已顺带算出（见 WithHashes），直接返回结果；否则需要可 Seek 的数据源，
+// 网络流等不可 Seek 的数据源请先 WithHashes 再 SaveTo/Body。
+func (f *Filer) Hash(algos ...HashAlgo) (Hashes, error) {
+	sums, err := f.hash(algos)
+	if err != nil {
+		return nil, f.wrapError("hash", f.sourcePath(), err)
+	}
+	return sums, nil
+}
+
+// hash 执行 Hash
+func (f *Filer) hash(algos []HashAlgo) (Hashes, error) {
+	if len(algos) == 0 {
+		algos = []HashAlgo{HashSHA256}
+	}
+	if sums, ok := f.cachedHashes(algos); ok {
+		return sums, nil
+	}
+	if f.readCloser == nil {
+		return nil, ErrNotOpened
+	}
+	if _, ok := f.readCloser.(io.Seeker); !ok {
+		return nil, ErrNotSeekable
+	}
+	s, err := newHashSet(algos)
+	if err != nil {
+		return nil, err
+	}
+	if err = f.seekStart(); err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(s, f.reader()); err != nil {
+		return nil, err
+	}
+	if err = f.seekStart(); err != nil {
+		return nil, err
+	}
+	sums := s.sums()
+	f.storeHashes(sums)
+	return sums, nil
+}
+
+// cachedHashes 返回已计算过的 algos 摘要
+func (f *Filer) cachedHashes(algos []HashAlgo) (Hashes, bool) {
+	sums := make(Hashes, len(algos))
+	for _, algo := range algos {
+		sum, ok := f.hashes[algo]
+		if !ok {
+			return nil, false
+		}
+		sums[algo] = sum
+	}
+	return sums, true
+}
+
+func (f *Filer) storeHashes(sums Hashes) {
+	if f.hashes == nil {
+		f.hashes = make(Hashes, len(sums))
+	}
+	for algo, sum := range sums {
+		f.hashes[algo] = sum
+	}
+}
+
+// expectedChecksums 需要校验的摘要：WithVerifyChecksum 与响应头中的摘要
+func (f *Filer) expectedChecksums() []Checksum {
+	return append(slices.Clip(f.opts.checksums), f.headerChecksums...)
+}
+
+// hashingReader 返回从当前位置读取的 reader，读取的内容同时计算 WithHashes 与待校验的摘要。
+// 调用方完整读完后调用 finish：保存摘要供 Hash 使用，并校验期望值；未配置任何摘要时不额外计算。
+func (f *Filer) hashingReader() (io.Reader, func() error, error) {
+	expected := f.expectedChecksums()
+	algos := slices.Clone(f.opts.hashAlgos)
+	for _, c := range expected {
+		algos = append(algos, c.Algo)
+	}
+	if len(algos) == 0 {
+		return f.reader(), func() error { return nil }, nil
+	}
+	s, err := newHashSet(algos)
+	if err != nil {
+		return nil, nil, err
+	}
+	finish := func() error {
+		sums := s.sums()
+		f.storeHashes(sums)
+		for _, c := range expected {
+			if actual := sums[c.Algo]; !strings.EqualFold(actual, c.Value) {
+				return &ChecksumError{Algo: c.Algo, Expected: strings.ToLower(c.Value), Actual: actual}
+			}
+		}
+		return nil
+	}
+	return io.TeeReader(f.reader(), s), finish, nil
+}
+
+// verifyingReader 读到 EOF 时执行 finish，失败时以其错误代替 io.EOF 返回，使消费方（如 Storage.Put）中止写入
+type verifyingReader struct {
+	r      io.Reader
+	finish func() error
+	done   bool
+	err    error
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	if v.done {
+		return 0, v.err
+	}
+	n, err := v.r.Read(p)
+	if err == io.EOF {
+		err = v.verify()
+	}
+	return n, err
+}
+
+// verify 只执行一次 finish，成功时返回 io.EOF
+func (v *verifyingReader) verify() error {
+	if !v.done {
+		v.done = true
+		if v.err = v.finish(); v.err == nil {
+			v.err = io.EOF
+		}
+	}
+	return v.err
+}
+
+// digestAlgos Digest / Repr-Digest 中的算法名（不区分大小写）
+var digestAlgos = map[string]HashAlgo{
+	"md5":     HashMD5,
+	"sha":     HashSHA1,
+	"sha-256": HashSHA256,
+	"sha-512": HashSHA512,
+}
+
+// headerChecksums 解析响应头中的 Content-MD5、Digest（RFC 3230）与 Repr-Digest（RFC 9530），摘要值为 base64
+func headerChecksums(header http.Header) []Checksum {
+	var checksums []Checksum
+	add := func(algo HashAlgo, b64 string) {
+		sum, err := base64.StdEncoding.DecodeString(strings.Trim(strings.TrimSpace(b64), ":"))
+		if err != nil || len(sum) == 0 {
+			return
+		}
+		value := hex.EncodeToString(sum)
+		if !slices.Contains(checksums, Checksum{Algo: algo, Value: value}) {
+			checksums = append(checksums, Checksum{Algo: algo, Value: value})
+		}
+	}
+	if v := header.Get("Content-MD5"); v != "" {
+		add(HashMD5, v)
+	}
+	for _, name := range []string{"Digest", "Repr-Digest"} {
+		for _, line := range header.Values(name) {
+			for _, item := range strings.Split(line, ",") {
+				k, v, ok := strings.Cut(strings.TrimSpace(item), "=")
+				if !ok {
+					continue
+				}
+				if algo, ok := digestAlgos[strings.ToLower(strings.TrimSpace(k))]; ok {
+					add(algo, v)
+				}
+			}
+		}
+	}
+	return checksums
+}
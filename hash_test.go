@@ -0,0 +1,165 @@
+package filer_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHash 验证一次读取计算多种摘要，且不影响之后的读取。
+func TestHash(t *testing.T) {
+	f := filer.NewFiler()
+	require.NoError(t, f.Open([]byte("abc")))
+	sums, err := f.Hash(filer.HashMD5, filer.HashSHA1, filer.HashSHA256, filer.HashSHA512, filer.HashCRC32)
+	require.NoError(t, err)
+	assert.Equal(t, filer.Hashes{
+		filer.HashMD5:    "900150983cd24fb0d6963f7d28e17f72",
+		filer.HashSHA1:   "a9993e364706816aba3e25717850c26c9cd0d89d",
+		filer.HashSHA256: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		filer.HashSHA512: "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f",
+		filer.HashCRC32:  "352441c2",
+	}, sums)
+
+	body, err := f.Body()
+	require.NoError(t, err)
+	assert.Equal(t, "abc", string(body))
+
+	_, err = f.Hash("whirlpool")
+	assert.ErrorIs(t, err, filer.ErrUnsupportedHash)
+}
+
+// TestHash_SideEffect 验证 WithHashes 在 SaveTo 时顺带计算摘要，不可 Seek 的流无需再次读取。
+func TestHash_SideEffect(t *testing.T) {
+	content := []byte("streamed once")
+	f := filer.NewFiler(filer.WithHashes(filer.HashSHA256, filer.HashMD5))
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open(struct{ io.Reader }{bytes.NewReader(content)}))
+
+	_, err := f.Hash()
+	assert.ErrorIs(t, err, filer.ErrNotSeekable)
+
+	_, err = f.SaveTo(filepath.Join(t.TempDir(), "out.bin"))
+	require.NoError(t, err)
+	sums, err := f.Hash(filer.HashSHA256, filer.HashMD5)
+	require.NoError(t, err)
+	sha := sha256.Sum256(content)
+	md := md5.Sum(content)
+	assert.Equal(t, sha[:], mustHex(t, sums[filer.HashSHA256]))
+	assert.Equal(t, md[:], mustHex(t, sums[filer.HashMD5]))
+}
+
+// TestVerifyChecksum 验证摘要不一致时 SaveTo 失败且不留下文件，Body 同样返回错误。
+func TestVerifyChecksum(t *testing.T) {
+	dir := t.TempDir()
+	const abcSHA256 = "BA7816BF8F01CFEA414140DE5DAE2223B00361A396177A9CB410FF61F20015AD"
+
+	f := filer.NewFiler(filer.WithVerifyChecksum(filer.HashSHA256, abcSHA256))
+	require.NoError(t, f.Open([]byte("abc")))
+	_, err := f.SaveTo(filepath.Join(dir, "ok.txt"))
+	require.NoError(t, err)
+
+	f = filer.NewFiler(filer.WithVerifyChecksum(filer.HashSHA256, abcSHA256))
+	require.NoError(t, f.Open([]byte("abd")))
+	_, err = f.SaveTo(filepath.Join(dir, "bad.txt"))
+	var checksumErr *filer.ChecksumError
+	require.True(t, errors.As(err, &checksumErr))
+	assert.Equal(t, filer.HashSHA256, checksumErr.Algo)
+	assert.ErrorIs(t, err, filer.ErrChecksumMismatch)
+	_, err = os.Stat(filepath.Join(dir, "bad.txt"))
+	assert.True(t, os.IsNotExist(err))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	_, err = f.Body()
+	assert.ErrorIs(t, err, filer.ErrChecksumMismatch)
+}
+
+// TestVerifyChecksum_Storage 验证校验失败时 Put 随之失败，不写入新对象，也不覆盖已有对象。
+func TestVerifyChecksum_Storage(t *testing.T) {
+	storage := filer.NewMemoryStorage("")
+	f := filer.NewFiler(filer.WithVerifyChecksum(filer.HashMD5, "00000000000000000000000000000000"))
+	require.NoError(t, f.Open([]byte("abc")))
+	_, err := f.SaveToStorage(t.Context(), storage, "a.txt")
+	assert.ErrorIs(t, err, filer.ErrChecksumMismatch)
+	assert.Empty(t, storage.Keys())
+
+	for name, s := range map[string]filer.Storage{
+		"memory": filer.NewMemoryStorage(""),
+		"local":  filer.NewLocalStorage(t.TempDir(), ""),
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, s.Put(t.Context(), "a.txt", strings.NewReader("precious"), filer.PutOptions{}))
+			f := filer.NewFiler(filer.WithVerifyChecksum(filer.HashMD5, "00000000000000000000000000000000"))
+			require.NoError(t, f.Open([]byte("abc")))
+			_, err := f.SaveToStorage(t.Context(), s, "a.txt")
+			assert.ErrorIs(t, err, filer.ErrChecksumMismatch)
+			rc, err := s.Get(t.Context(), "a.txt")
+			require.NoError(t, err)
+			defer rc.Close()
+			b, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			assert.Equal(t, "precious", string(b))
+		})
+	}
+}
+
+// TestVerifyHeaderChecksum 验证按 Content-MD5 与 Digest 响应头校验网络源。
+func TestVerifyHeaderChecksum(t *testing.T) {
+	content := []byte("remote content")
+	md := md5.Sum(content)
+	sha := sha256.Sum256(content)
+	tests := []struct {
+		name    string
+		header  map[string]string
+		wantErr bool
+	}{
+		{"content-md5", map[string]string{"Content-MD5": base64.StdEncoding.EncodeToString(md[:])}, false},
+		{"digest", map[string]string{"Digest": "SHA-256=" + base64.StdEncoding.EncodeToString(sha[:])}, false},
+		{"repr-digest", map[string]string{"Repr-Digest": "sha-256=:" + base64.StdEncoding.EncodeToString(sha[:]) + ":"}, false},
+		{"mismatch", map[string]string{"Content-MD5": base64.StdEncoding.EncodeToString(sha[:16])}, true},
+		{"unknown algo", map[string]string{"Digest": "unixsum=30637"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header().Set(k, v)
+				}
+				_, _ = w.Write(content)
+			}))
+			defer srv.Close()
+
+			f := filer.NewFiler(filer.WithVerifyHeaderChecksum(true))
+			defer func() { _ = f.Close() }()
+			require.NoError(t, f.Open(srv.URL+"/a.bin"))
+			_, err := f.SaveTo(filepath.Join(t.TempDir(), "a.bin"))
+			if tt.wantErr {
+				assert.ErrorIs(t, err, filer.ErrChecksumMismatch)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
@@ -2,14 +2,12 @@ package filer
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"image"
 	"image/gif"
 	"image/jpeg"
 	"image/png"
 	"io"
-	"os"
 	"strings"
 	"sync"
 
@@ -30,6 +28,8 @@ type Imager struct {
 	rawOnce    sync.Once
 	rawBuf     []byte
 	rawLoadErr error
+
+	savedPath string // 最近一次 SaveTo 实际写入的路径
 }
 
 // newImager 创建 Imager 实例
@@ -46,7 +46,7 @@ func newImager(filer *Filer) (*Imager, error) {
 		seeker = s
 	} else {
 		var data []byte
-		data, err = io.ReadAll(rc)
+		data, err = io.ReadAll(imager.reader())
 		if err != nil {
 			return imager, err
 		}
@@ -59,7 +59,7 @@ func newImager(filer *Filer) (*Imager, error) {
 		return imager, err
 	}
 
-	img, format, err := image.Decode(rc)
+	img, format, err := imager.decodeImage()
 	if err != nil {
 		return imager, err
 	}
@@ -134,12 +134,9 @@ func (img *Imager) Mode() string {
 
 // Resize 缩放图像
 func (img *Imager) Resize(width, height int) error {
-	if err := img.seekStart(); err != nil {
-		return err
-	}
-	origin, _, err := image.Decode(img.readCloser)
+	origin, _, err := img.decodeImage()
 	if err != nil {
-		return err
+		return img.wrapError("resize", img.sourcePath(), err)
 	}
 	img.rgba = imaging.Resize(origin, width, height, imaging.Lanczos)
 	img.syncSizeFromRGBA()
@@ -148,12 +145,9 @@ func (img *Imager) Resize(width, height int) error {
 
 // Crop 裁剪图像
 func (img *Imager) Crop(width, height int) error {
-	if err := img.seekStart(); err != nil {
-		return err
-	}
-	origin, _, err := image.Decode(img.readCloser)
+	origin, _, err := img.decodeImage()
 	if err != nil {
-		return err
+		return img.wrapError("crop", img.sourcePath(), err)
 	}
 	img.rgba = imaging.CropAnchor(origin, width, height, imaging.Center)
 	img.syncSizeFromRGBA()
@@ -166,12 +160,12 @@ func (img *Imager) Body() ([]byte, error) {
 	if img.rgba != nil {
 		var buf bytes.Buffer
 		if err := img.encodeTo(&buf); err != nil {
-			return nil, err
+			return nil, img.wrapError("read", img.sourcePath(), err)
 		}
 		return buf.Bytes(), nil
 	}
 	if err := img.loadSourceBytes(); err != nil {
-		return nil, err
+		return nil, img.wrapError("read", img.sourcePath(), err)
 	}
 	return append([]byte(nil), img.rawBuf...), nil
 }
@@ -180,14 +174,14 @@ func (img *Imager) Body() ([]byte, error) {
 func (img *Imager) loadSourceBytes() error {
 	img.rawOnce.Do(func() {
 		if img.readCloser == nil {
-			img.rawLoadErr = errors.New("imager: no read source")
+			img.rawLoadErr = ErrNotOpened
 			return
 		}
 		if err := img.seekStart(); err != nil {
 			img.rawLoadErr = err
 			return
 		}
-		b, err := io.ReadAll(img.readCloser)
+		b, err := io.ReadAll(img.reader())
 		if err != nil {
 			img.rawLoadErr = err
 			return
@@ -214,7 +208,7 @@ func (img *Imager) encodeTo(w io.Writer) error {
 	case ".webp":
 		return encodeWebP(w, img.rgba, img.quality)
 	default:
-		return fmt.Errorf("imager: cannot decide output format")
+		return ErrUnknownFormat
 	}
 }
 
@@ -241,28 +235,64 @@ func (img *Imager) outputFormat() string {
 	}
 }
 
-// SaveTo 将图像写入 path（rgba 为空则写出惰性缓存的原始字节）。
+// SaveTo 将图像写入 path（rgba 为空则写出惰性缓存的原始字节），目标已存在时按 WithConflictPolicy 处理，
+// 实际写入的路径（如 ConflictRename 改名后的路径）可通过 SavedPath 获取。
 // 与嵌入的 (*Filer).SaveTo 同名：对 *Imager 调用 SaveTo 为本方法；需 Filer 的目录规则与返回值请用 img.Filer.SaveTo(...)。
-func (img *Imager) SaveTo(path string) (err error) {
+func (img *Imager) SaveTo(path string) error {
 	path = strings.TrimSpace(path)
+	if err := img.saveTo(path); err != nil {
+		return img.wrapError("save", path, err)
+	}
+	return nil
+}
+
+// SavedPath 最近一次 SaveTo 成功时实际写入的路径（ConflictSkip 跳过时为已存在的文件），尚未保存时为空字符串
+func (img *Imager) SavedPath() string {
+	return img.savedPath
+}
+
+// saveTo 执行 SaveTo，与 Filer.SaveTo 一样先写临时文件再重命名
+func (img *Imager) saveTo(path string) error {
 	if path == "" {
-		return errors.New("imager: path is empty")
+		return ErrEmptyPath
 	}
+	if err := img.validateTarget(path); err != nil {
+		return err
+	}
+	if err := img.checkStrictTarget(path); err != nil {
+		return err
+	}
+	write, size := img.encodeTo, int64(-1)
 	if img.rgba == nil {
 		if err := img.loadSourceBytes(); err != nil {
 			return err
 		}
-		return os.WriteFile(path, img.rawBuf, 0644)
+		write, size = func(w io.Writer) error {
+			_, err := w.Write(img.rawBuf)
+			return err
+		}, int64(len(img.rawBuf))
 	}
-	f, err := os.Create(path)
+	// 编码后的大小未知，在写入过程中检查配额
+	quota, err := img.checkQuotaSize(path, size, img.opts.conflict == ConflictOverwrite)
 	if err != nil {
 		return err
 	}
-	defer func(f *os.File) {
-		if cerr := f.Close(); err == nil && cerr != nil {
-			err = cerr
+	var qw *quotaWriter
+	savedPath, written, err := writeFileWithPolicy(path, func(w io.Writer) error {
+		if quota != nil {
+			qw = &quotaWriter{w: w, st: quota}
+			w = qw
 		}
-	}(f)
-
-	return img.encodeTo(f)
+		return write(w)
+	}, img.opts.conflict, img.opts.syncDir)
+	if err != nil {
+		return err
+	}
+	if written && qw != nil {
+		if err = qw.commit(); err != nil {
+			return err
+		}
+	}
+	img.savedPath = savedPath
+	return nil
 }
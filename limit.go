@@ -0,0 +1,161 @@
+package filer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+)
+
+// SizeLimitError 文件超过 WithMaxSize 设置的上限，errors.Is(err, ErrTooLarge) 成立
+type SizeLimitError struct {
+	Limit int64 // 允许的最大字节数
+	Size  int64 // 已知的文件大小，流式读取时无法预知则为 -1
+}
+
+func (e *SizeLimitError) Error() string {
+	if e.Size < 0 {
+		return fmt.Sprintf("filer: file exceeds the %d bytes limit", e.Limit)
+	}
+	return fmt.Sprintf("filer: file size %d exceeds the %d bytes limit", e.Size, e.Limit)
+}
+
+// Is 使 errors.Is(err, ErrTooLarge) 成立
+func (e *SizeLimitError) Is(target error) bool {
+	return target == ErrTooLarge
+}
+
+// WithMaxSize 限制可读取的文件大小（字节），<= 0 表示不限制。
+// 大小可预知时（Content-Length、Data URI 长度、文件 Stat、表单头等）在 Open 中直接拒绝；
+// 否则在读取时流式计数，超出即返回 *SizeLimitError，不会把超限内容整体读入内存。
+func WithMaxSize(size int64) Option {
+	return func(o *options) {
+		o.maxSize = size
+	}
+}
+
+// ImageLimits 图像完整解码前按 image.DecodeConfig 声明的尺寸检查的上限，防止像素极多的小文件（解压炸弹）耗尽内存。
+// 各字段 <= 0 表示不限制。
+type ImageLimits struct {
+	MaxPixels int64 // 最大像素数（宽 × 高）
+	MaxWidth  int   // 最大宽度
+	MaxHeight int   // 最大高度
+}
+
+// DefaultImageLimits 未通过 WithImageLimits 指定时使用的上限，约 1 亿像素（解码为 NRGBA 约 400MB）
+var DefaultImageLimits = ImageLimits{MaxPixels: 100_000_000}
+
+// WithImageLimits 设置 Imager()、Resize、Crop 解码图像前的尺寸上限，传入零值 ImageLimits{} 表示不限制
+func WithImageLimits(limits ImageLimits) Option {
+	return func(o *options) {
+		o.imageLimits = &limits
+	}
+}
+
+// ImageLimitError 图像声明的尺寸超过 ImageLimits，errors.Is(err, ErrImageTooLarge) 成立
+type ImageLimitError struct {
+	Width  int         // 图像声明的宽度
+	Height int         // 图像声明的高度
+	Limits ImageLimits // 生效的上限
+}
+
+func (e *ImageLimitError) Error() string {
+	return fmt.Sprintf("filer: image %dx%d exceeds the limits (max pixels %d, max width %d, max height %d)",
+		e.Width, e.Height, e.Limits.MaxPixels, e.Limits.MaxWidth, e.Limits.MaxHeight)
+}
+
+// Is 使 errors.Is(err, ErrImageTooLarge) 成立
+func (e *ImageLimitError) Is(target error) bool {
+	return target == ErrImageTooLarge
+}
+
+// exceeds 判断 width × height 是否超出上限
+func (l ImageLimits) exceeds(width, height int) bool {
+	return (l.MaxWidth > 0 && width > l.MaxWidth) ||
+		(l.MaxHeight > 0 && height > l.MaxHeight) ||
+		(l.MaxPixels > 0 && int64(width)*int64(height) > l.MaxPixels)
+}
+
+// imageLimits 返回生效的图像尺寸上限
+func (f *Filer) imageLimits() ImageLimits {
+	if f.opts.imageLimits != nil {
+		return *f.opts.imageLimits
+	}
+	return DefaultImageLimits
+}
+
+// decodeImage 从头完整解码图像，解码前先用 image.DecodeConfig 检查尺寸上限
+func (f *Filer) decodeImage() (image.Image, string, error) {
+	if err := f.seekStart(); err != nil {
+		return nil, "", err
+	}
+	if limits := f.imageLimits(); limits != (ImageLimits{}) {
+		// DecodeConfig 失败时交给 image.Decode 报告错误
+		if cfg, _, err := image.DecodeConfig(f.reader()); err == nil && limits.exceeds(cfg.Width, cfg.Height) {
+			return nil, "", &ImageLimitError{Width: cfg.Width, Height: cfg.Height, Limits: limits}
+		}
+		if err := f.seekStart(); err != nil {
+			return nil, "", err
+		}
+	}
+	return image.Decode(f.reader())
+}
+
+// limitedReadCloser 读取超过 limit 字节时返回 *SizeLimitError
+type limitedReadCloser struct {
+	io.ReadCloser
+	limit int64
+	read  int64
+}
+
+func (r *limitedReadCloser) Read(p []byte) (int, error) {
+	if r.read > r.limit {
+		return 0, &SizeLimitError{Limit: r.limit, Size: -1}
+	}
+	// 多读 1 字节用于判断是否超限
+	if remaining := r.limit - r.read + 1; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	if r.read > r.limit {
+		n -= int(r.read - r.limit)
+		return n, &SizeLimitError{Limit: r.limit, Size: -1}
+	}
+	return n, err
+}
+
+// base64DecodedLen 计算 base64 内容解码后的准确长度，用于解码前的大小检查
+func base64DecodedLen(s string) int64 {
+	n := base64.StdEncoding.DecodedLen(len(s))
+	if strings.HasSuffix(s, "==") {
+		n -= 2
+	} else if strings.HasSuffix(s, "=") {
+		n--
+	}
+	return int64(n)
+}
+
+// checkMaxSize 在 Open 结束时执行大小限制：可 Seek 或大小已知的源立即比较，其余源改为流式限制。
+func (f *Filer) checkMaxSize() error {
+	limit := f.opts.maxSize
+	if limit <= 0 || f.readCloser == nil {
+		return nil
+	}
+	if _, ok := f.readCloser.(io.Seeker); ok {
+		size, err := f.sizeOf()
+		if err != nil {
+			return err
+		}
+		if size > limit {
+			return &SizeLimitError{Limit: limit, Size: size}
+		}
+		return nil
+	}
+	if f.size > limit {
+		return &SizeLimitError{Limit: limit, Size: f.size}
+	}
+	f.readCloser = &limitedReadCloser{ReadCloser: f.readCloser, limit: limit}
+	return nil
+}
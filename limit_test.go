@@ -0,0 +1,141 @@
+package filer_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithMaxSize_KnownSize 验证大小可预知的源在 Open 时即被拒绝。
+func TestWithMaxSize_KnownSize(t *testing.T) {
+	payload := bytes.Repeat([]byte("a"), 100)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(payload)
+	}))
+	defer srv.Close()
+
+	sources := map[string]any{
+		"network":  srv.URL + "/a.txt",
+		"data-uri": "data:text/plain;base64," + base64.StdEncoding.EncodeToString(payload),
+		"bytes":    payload,
+		"text":     string(payload),
+		"local":    filepath.Join("tests", "test.jpg"),
+	}
+	for name, src := range sources {
+		t.Run(name, func(t *testing.T) {
+			f := filer.NewFiler(filer.WithMaxSize(50))
+			defer func() { _ = f.Close() }()
+			err := f.Open(src)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, filer.ErrTooLarge)
+			var limitErr *filer.SizeLimitError
+			require.True(t, errors.As(err, &limitErr))
+			assert.Equal(t, int64(50), limitErr.Limit)
+		})
+	}
+}
+
+// TestWithMaxSize_Streaming 验证大小未知（chunked）的网络源在读取时被截断并报错。
+func TestWithMaxSize_Streaming(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for range 10 {
+			_, _ = w.Write(bytes.Repeat([]byte("a"), 1024))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer srv.Close()
+
+	f := filer.NewFiler(filer.WithMaxSize(4096))
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open(srv.URL+"/a.txt"))
+	_, err := f.Body()
+	assert.ErrorIs(t, err, filer.ErrTooLarge)
+	assert.False(t, f.IsImage())
+
+	f2 := filer.NewFiler(filer.WithMaxSize(20 * 1024))
+	defer func() { _ = f2.Close() }()
+	require.NoError(t, f2.Open(srv.URL+"/a.txt"))
+	b, err := f2.Body()
+	require.NoError(t, err)
+	assert.Len(t, b, 10*1024)
+}
+
+// TestWithMaxSize_WithinLimit 验证未超限时行为不变。
+func TestWithMaxSize_WithinLimit(t *testing.T) {
+	f := filer.NewFiler(filer.WithMaxSize(1 << 20))
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open(filepath.Join("tests", "test.jpg")))
+	size, err := f.Size()
+	require.NoError(t, err)
+	assert.Equal(t, int64(11876), size)
+}
+
+// pngHeaderFixture 只含文件头与 IHDR 的 PNG，声明 w×h 像素而不携带像素数据
+func pngHeaderFixture(w, h uint32) []byte {
+	ihdr := make([]byte, 0, 17)
+	ihdr = append(ihdr, "IHDR"...)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, w)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, h)
+	ihdr = append(ihdr, 8, 6, 0, 0, 0) // 8 位 RGBA
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, 13)
+	data = append(data, ihdr...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
+
+// TestImageLimits_Default 验证默认上限在完整解码前拒绝声明尺寸巨大的图像。
+func TestImageLimits_Default(t *testing.T) {
+	f := filer.NewFiler()
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open(pngHeaderFixture(50000, 50000)))
+	assert.True(t, f.IsImage())
+
+	_, err := f.Imager()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, filer.ErrImageTooLarge))
+	var limitErr *filer.ImageLimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, 50000, limitErr.Width)
+	assert.Equal(t, 50000, limitErr.Height)
+	assert.Equal(t, filer.DefaultImageLimits, limitErr.Limits)
+}
+
+func TestWithImageLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits filer.ImageLimits
+		ok     bool
+	}{
+		{"max width", filer.ImageLimits{MaxWidth: 15}, false},
+		{"max height", filer.ImageLimits{MaxHeight: 7}, false},
+		{"max pixels", filer.ImageLimits{MaxPixels: 127}, false},
+		{"within limits", filer.ImageLimits{MaxPixels: 128, MaxWidth: 16, MaxHeight: 8}, true},
+		{"unlimited", filer.ImageLimits{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := filer.NewFiler(filer.WithImageLimits(tt.limits))
+			defer func() { _ = f.Close() }()
+			require.NoError(t, f.Open(pngFixture(16, 8)))
+			img, err := f.Imager()
+			if !tt.ok {
+				assert.True(t, errors.Is(err, filer.ErrImageTooLarge))
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, img.Resize(4, 2))
+			require.NoError(t, img.Crop(2, 2))
+			assert.Equal(t, 2, img.Width())
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package filer
+
+import (
+	"mime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MIMERegistry MIME 类型与扩展名的映射表，用于按内容类型选择扩展名（Ext()、网络文件名）以及按扩展名推断
+// Content-Type（存储后端）。未登记的类型回退到标准库 mime 包。可并发使用。
+type MIMERegistry struct {
+	mu    sync.RWMutex
+	exts  map[string][]string // MIME 类型 -> 扩展名，第一个为首选扩展名
+	types map[string]string   // 扩展名 -> MIME 类型
+}
+
+// DefaultMIMERegistry 未通过 WithMIMERegistry 指定时使用的全局映射表，修改会影响所有 Filer 与存储后端
+var DefaultMIMERegistry = newDefaultMIMERegistry()
+
+// NewMIMERegistry 创建空映射表，可用 DefaultMIMERegistry.Clone() 在默认映射的基础上定制
+func NewMIMERegistry() *MIMERegistry {
+	return &MIMERegistry{
+		exts:  make(map[string][]string),
+		types: make(map[string]string),
+	}
+}
+
+func newDefaultMIMERegistry() *MIMERegistry {
+	r := NewMIMERegistry()
+	for _, m := range []struct {
+		mimeType string
+		ext      string
+	}{
+		// 图片
+		{"image/jpeg", ".jpeg"},
+		{"image/png", ".png"},
+		{"image/gif", ".gif"},
+		{"image/webp", ".webp"},
+		{"image/bmp", ".bmp"},
+		{"image/svg+xml", ".svg"},
+		{"image/tiff", ".tiff"},
+		{"image/x-icon", ".ico"},
+
+		// 文本
+		{"text/plain", ".txt"},
+		{"text/html", ".html"},
+		{"text/css", ".css"},
+		{"text/javascript", ".js"},
+		{"text/csv", ".csv"},
+		{"text/xml", ".xml"},
+
+		// 应用
+		{"application/json", ".json"},
+		{"application/pdf", ".pdf"},
+		{"application/zip", ".zip"},
+		{"application/gzip", ".gz"},
+		{"application/x-tar", ".tar"},
+		{"application/rar", ".rar"},
+		{"application/x-7z-compressed", ".7z"},
+		{"application/msword", ".doc"},
+		{"application/vnd.openxmlformats-officedocument.wordprocessingml.document", ".docx"},
+		{"application/vnd.ms-excel", ".xls"},
+		{"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", ".xlsx"},
+		{"application/vnd.mozilla.xul+xml", ".xul"},
+		{"application/x-shockwave-flash", ".swf"},
+		{"application/xhtml+xml", ".xhtml"},
+		{"application/rtf", ".rtf"},
+
+		// 音频
+		{"audio/mpeg", ".mp3"},
+		{"audio/wav", ".wav"},
+		{"audio/ogg", ".ogg"},
+		{"audio/aac", ".aac"},
+		{"audio/flac", ".flac"},
+
+		// 视频
+		{"video/mp4", ".mp4"},
+		{"video/webm", ".webm"},
+		{"video/ogg", ".ogv"},
+		{"video/quicktime", ".mov"},
+		{"video/x-msvideo", ".avi"},
+	} {
+		r.Register(m.mimeType, m.ext)
+	}
+	return r
+}
+
+// normalizeMIMEType 去掉参数并转为小写，如 "Text/Plain; charset=utf-8" -> "text/plain"
+func normalizeMIMEType(mimeType string) string {
+	return strings.ToLower(strings.TrimSpace(mimeBaseType(mimeType)))
+}
+
+// normalizeExt 转为带 "." 的小写扩展名
+func normalizeExt(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// Register 登记 mimeType 对应的扩展名（如 ".heic"，可省略 "."）。mimeType 尚无扩展名时 exts[0] 成为首选扩展名，
+// 否则追加在已有扩展名之后；同时把这些扩展名反查的类型设置为 mimeType（覆盖已有映射）。
+func (r *MIMERegistry) Register(mimeType string, exts ...string) {
+	mimeType = normalizeMIMEType(mimeType)
+	if mimeType == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ext := range exts {
+		if ext = normalizeExt(ext); ext == "" {
+			continue
+		}
+		if !slices.Contains(r.exts[mimeType], ext) {
+			r.exts[mimeType] = append(r.exts[mimeType], ext)
+		}
+		r.types[ext] = mimeType
+	}
+}
+
+// SetPreferredExt 设置 mimeType 的首选扩展名（如把 image/jpeg 设为 ".jpg"），ext 未登记时一并登记
+func (r *MIMERegistry) SetPreferredExt(mimeType, ext string) {
+	mimeType, ext = normalizeMIMEType(mimeType), normalizeExt(ext)
+	if mimeType == "" || ext == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exts := slices.DeleteFunc(slices.Clone(r.exts[mimeType]), func(e string) bool { return e == ext })
+	r.exts[mimeType] = append([]string{ext}, exts...)
+	if _, ok := r.types[ext]; !ok {
+		r.types[ext] = mimeType
+	}
+}
+
+// Ext 返回 mimeType 的首选扩展名（忽略参数与大小写），未登记时返回 false
+func (r *MIMERegistry) Ext(mimeType string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if exts := r.exts[normalizeMIMEType(mimeType)]; len(exts) != 0 {
+		return exts[0], true
+	}
+	return "", false
+}
+
+// Exts 返回 mimeType 登记的全部扩展名，首选扩展名在前
+func (r *MIMERegistry) Exts(mimeType string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.exts[normalizeMIMEType(mimeType)])
+}
+
+// MIMEType 按扩展名（忽略大小写，可省略 "."）反查 MIME 类型，未登记时返回 false
+func (r *MIMERegistry) MIMEType(ext string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	mimeType, ok := r.types[normalizeExt(ext)]
+	return mimeType, ok
+}
+
+// Clone 复制映射表，修改副本不影响原表
+func (r *MIMERegistry) Clone() *MIMERegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c := NewMIMERegistry()
+	for mimeType, exts := range r.exts {
+		c.exts[mimeType] = slices.Clone(exts)
+	}
+	for ext, mimeType := range r.types {
+		c.types[ext] = mimeType
+	}
+	return c
+}
+
+// contentType 按扩展名推断 Content-Type：先查映射表，再查标准库，均未知时为 application/octet-stream
+func (r *MIMERegistry) contentType(ext string) string {
+	if mimeType, ok := r.MIMEType(ext); ok {
+		return mimeType
+	}
+	if t := mime.TypeByExtension(strings.ToLower(ext)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// compatibleExt 判断 ext 是否与识别结果 res 一致：签名库认为一致，或 ext 是映射表中该类型登记的扩展名
+func (r *MIMERegistry) compatibleExt(res sniffResult, ext string) bool {
+	return res.compatibleExt(ext) || (ext != "" && slices.Contains(r.Exts(res.mimeType), ext))
+}
+
+// WithMIMERegistry 为 Filer 指定 MIME 映射表，不同服务可使用不同的扩展名约定；为空时使用 DefaultMIMERegistry
+func WithMIMERegistry(registry *MIMERegistry) Option {
+	return func(o *options) {
+		o.mimeRegistry = registry
+	}
+}
+
+// mimeRegistry 返回 Filer 使用的 MIME 映射表
+func (f *Filer) mimeRegistry() *MIMERegistry {
+	if f.opts.mimeRegistry != nil {
+		return f.opts.mimeRegistry
+	}
+	return DefaultMIMERegistry
+}
@@ -0,0 +1,80 @@
+package filer_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMIMERegistry(t *testing.T) {
+	r := filer.NewMIMERegistry()
+	r.Register("image/jpeg", ".jpeg", "JPG", ".jpe")
+
+	ext, ok := r.Ext("Image/JPEG; q=1")
+	require.True(t, ok)
+	assert.Equal(t, ".jpeg", ext)
+	assert.Equal(t, []string{".jpeg", ".jpg", ".jpe"}, r.Exts("image/jpeg"))
+
+	mimeType, ok := r.MIMEType("jpg")
+	require.True(t, ok)
+	assert.Equal(t, "image/jpeg", mimeType)
+
+	r.SetPreferredExt("image/jpeg", ".jpg")
+	ext, _ = r.Ext("image/jpeg")
+	assert.Equal(t, ".jpg", ext)
+	assert.Equal(t, []string{".jpg", ".jpeg", ".jpe"}, r.Exts("image/jpeg"))
+
+	_, ok = r.Ext("image/heic")
+	assert.False(t, ok)
+	_, ok = r.MIMEType(".heic")
+	assert.False(t, ok)
+
+	// 后登记的类型覆盖反查结果
+	r.Register("audio/x-wav", ".wav")
+	r.Register("audio/wav", ".wav")
+	mimeType, _ = r.MIMEType(".wav")
+	assert.Equal(t, "audio/wav", mimeType)
+}
+
+func TestMIMERegistry_Clone(t *testing.T) {
+	c := filer.DefaultMIMERegistry.Clone()
+	c.SetPreferredExt("image/jpeg", ".jpg")
+	c.Register("image/heic", ".heic")
+
+	ext, _ := c.Ext("image/jpeg")
+	assert.Equal(t, ".jpg", ext)
+	ext, _ = filer.DefaultMIMERegistry.Ext("image/jpeg")
+	assert.Equal(t, ".jpeg", ext)
+	_, ok := filer.DefaultMIMERegistry.MIMEType(".heic")
+	assert.False(t, ok)
+}
+
+// TestWithMIMERegistry 验证每个 Filer 可使用各自的扩展名约定。
+func TestWithMIMERegistry(t *testing.T) {
+	registry := filer.DefaultMIMERegistry.Clone()
+	registry.SetPreferredExt("image/jpeg", ".jpg")
+	registry.Register("text/markdown", ".md")
+
+	custom := filer.NewFiler(filer.WithMIMERegistry(registry))
+	defer func() { _ = custom.Close() }()
+	require.NoError(t, custom.Open(jpegFixture(4, 4)))
+	assert.Equal(t, ".jpg", custom.Ext())
+
+	def := filer.NewFiler()
+	defer func() { _ = def.Close() }()
+	require.NoError(t, def.Open(jpegFixture(4, 4)))
+	assert.Equal(t, ".jpeg", def.Ext())
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte("# title\n"))
+	}))
+	defer srv.Close()
+
+	require.NoError(t, custom.Open(srv.URL+"/download"))
+	assert.Equal(t, "download.md", custom.Name())
+}
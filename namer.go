@@ -0,0 +1,218 @@
+package filer
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Namer 文件命名策略：SaveTo、SaveToStorage 的目标为目录时据此生成文件名。
+// 返回值可以包含以 "/" 分隔的子目录（如 "2026/10/17/xxx.jpg"），会被规范化，".." 不会跳出目标目录。
+type Namer func(f *Filer) (string, error)
+
+// WithNamer 设置文件命名策略。设置后目标为目录时总是使用 namer 命名（忽略原文件名）；
+// 未设置时优先使用 Name()，为空时使用 UUIDv7Namer。
+func WithNamer(namer Namer) Option {
+	return func(o *options) {
+		o.namer = namer
+	}
+}
+
+// UUIDv4Namer 使用随机 UUID（版本 4）+ Ext() 命名
+func UUIDv4Namer() Namer {
+	return func(f *Filer) (string, error) {
+		id, err := newUUIDv4()
+		if err != nil {
+			return "", err
+		}
+		return id + f.Ext(), nil
+	}
+}
+
+// UUIDv7Namer 使用按时间排序的 UUID（版本 7）+ Ext() 命名
+func UUIDv7Namer() Namer {
+	return func(f *Filer) (string, error) {
+		id, err := newUUIDv7(time.Now())
+		if err != nil {
+			return "", err
+		}
+		return id + f.Ext(), nil
+	}
+}
+
+// ULIDNamer 使用 ULID（26 位 Crockford Base32，按时间排序）+ Ext() 命名
+func ULIDNamer() Namer {
+	return func(f *Filer) (string, error) {
+		id, err := newULID(time.Now())
+		if err != nil {
+			return "", err
+		}
+		return id + f.Ext(), nil
+	}
+}
+
+// HashNamer 使用内容 SHA-256 十六进制摘要的前 length 位 + Ext() 命名，length <= 0 或大于 64 时使用完整摘要。
+// 相同内容得到相同文件名；非 Seek 流会先缓冲为内存流。
+func HashNamer(length int) Namer {
+	return func(f *Filer) (string, error) {
+		sum, err := f.contentSHA256()
+		if err != nil {
+			return "", err
+		}
+		if length > 0 && length < len(sum) {
+			sum = sum[:length]
+		}
+		return sum + f.Ext(), nil
+	}
+}
+
+// SlugNamer 将原文件名转换为 slug（小写，字母数字以外的字符替换为 "-"）+ Ext() 命名，
+// 如 "My Photo (1).JPG" 得到 "my-photo-1.jpg"；原文件名为空或转换后为空时使用 UUIDv7Namer。
+func SlugNamer() Namer {
+	return func(f *Filer) (string, error) {
+		name := path.Base(strings.ReplaceAll(f.Name(), `\`, "/"))
+		slug := slugify(strings.TrimSuffix(name, path.Ext(name)))
+		if f.Name() == "" || slug == "" {
+			return UUIDv7Namer()(f)
+		}
+		return slug + strings.ToLower(f.Ext()), nil
+	}
+}
+
+// DateNamer 按日期分目录：layout 为 time.Format 布局（为空时使用 "2006/01/02"），文件名由 namer 生成（为空时使用 UUIDv7Namer），
+// 如 "2026/10/17/0192....jpg"。
+func DateNamer(layout string, namer Namer) Namer {
+	if layout == "" {
+		layout = "2006/01/02"
+	}
+	if namer == nil {
+		namer = UUIDv7Namer()
+	}
+	return func(f *Filer) (string, error) {
+		name, err := namer(f)
+		if err != nil {
+			return "", err
+		}
+		return time.Now().Format(layout) + "/" + name, nil
+	}
+}
+
+// targetName 目标为目录时使用的文件名：优先 WithNamer，其次 Name()，最后 UUIDv7Namer
+func (f *Filer) targetName() (string, error) {
+	namer := f.opts.namer
+	if namer == nil {
+		if name := f.Name(); name != "" {
+			return name, nil
+		}
+		namer = UUIDv7Namer()
+	}
+	name, err := namer(f)
+	if err != nil {
+		return "", fmt.Errorf("generate file name failed, %w", err)
+	}
+	return cleanKey(name)
+}
+
+// contentSHA256 计算内容的 SHA-256 十六进制摘要，完成后流回到起始位置
+func (f *Filer) contentSHA256() (string, error) {
+	if err := f.ensureSeekable(); err != nil {
+		return "", err
+	}
+	if err := f.seekStart(); err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f.reader()); err != nil {
+		return "", err
+	}
+	if err := f.seekStart(); err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	f.storeHashes(Hashes{HashSHA256: sum})
+	return sum, nil
+}
+
+// slugify 转为小写，连续的非字母数字字符替换为单个 "-"，并去掉首尾的 "-"
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// newUUIDv4 生成随机 UUID（RFC 9562 版本 4）
+func newUUIDv4() (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return "", err
+	}
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return formatUUID(u), nil
+}
+
+// newUUIDv7 生成 UUID（RFC 9562 版本 7）：前 48 位为 Unix 毫秒时间戳，其余为随机数
+func newUUIDv7(t time.Time) (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[6:]); err != nil {
+		return "", err
+	}
+	ms := uint64(t.UnixMilli())
+	u[0], u[1], u[2] = byte(ms>>40), byte(ms>>32), byte(ms>>24)
+	u[3], u[4], u[5] = byte(ms>>16), byte(ms>>8), byte(ms)
+	u[6] = u[6]&0x0f | 0x70
+	u[8] = u[8]&0x3f | 0x80
+	return formatUUID(u), nil
+}
+
+func formatUUID(u [16]byte) string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// crockfordBase32 ULID 使用的 Crockford Base32 字母表
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID 生成 ULID：48 位 Unix 毫秒时间戳 + 80 位随机数，编码为 26 位 Crockford Base32
+func newULID(t time.Time) (string, error) {
+	var u [16]byte
+	binary.BigEndian.PutUint64(u[:8], uint64(t.UnixMilli())<<16)
+	if _, err := rand.Read(u[6:]); err != nil {
+		return "", err
+	}
+	hi := binary.BigEndian.Uint64(u[:8])
+	lo := binary.BigEndian.Uint64(u[8:])
+	// 128 位按 5 位一组编码，首字符只占 3 位
+	var buf [26]byte
+	for i := 25; i >= 0; i-- {
+		buf[i] = crockfordBase32[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(buf[:]), nil
+}
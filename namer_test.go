@@ -0,0 +1,111 @@
+package filer_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNamer_BuiltIn 验证内置命名策略生成的文件名格式。
+func TestNamer_BuiltIn(t *testing.T) {
+	content := []byte("namer content")
+	sum := sha256.Sum256(content)
+	tests := []struct {
+		name  string
+		namer filer.Namer
+		want  *regexp.Regexp
+	}{
+		{"uuid v4", filer.UUIDv4Namer(), regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}\.txt$`)},
+		{"uuid v7", filer.UUIDv7Namer(), regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}\.txt$`)},
+		{"ulid", filer.ULIDNamer(), regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}\.txt$`)},
+		{"hash", filer.HashNamer(16), regexp.MustCompile(`^` + hex.EncodeToString(sum[:])[:16] + `\.txt$`)},
+		{"full hash", filer.HashNamer(0), regexp.MustCompile(`^` + hex.EncodeToString(sum[:]) + `\.txt$`)},
+		{"slug", filer.SlugNamer(), regexp.MustCompile(`^my-report-2026\.txt$`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "My Report (2026).TXT")
+			require.NoError(t, os.WriteFile(path, content, 0644))
+
+			f := filer.NewFiler(filer.WithNamer(tt.namer))
+			defer func() { _ = f.Close() }()
+			require.NoError(t, f.Open(path))
+			saved, err := f.SaveTo(filepath.Join(dir, "out") + "/")
+			require.NoError(t, err)
+			assert.Regexp(t, tt.want, filepath.Base(saved))
+			got, err := os.ReadFile(saved)
+			require.NoError(t, err)
+			assert.Equal(t, content, got)
+		})
+	}
+}
+
+// TestNamer_TimeOrdered 验证 UUIDv7 与 ULID 按生成时间排序。
+func TestNamer_TimeOrdered(t *testing.T) {
+	f := filer.NewFiler()
+	require.NoError(t, f.Open([]byte("x")))
+	for _, namer := range []filer.Namer{filer.UUIDv7Namer(), filer.ULIDNamer()} {
+		first, err := namer(f)
+		require.NoError(t, err)
+		time.Sleep(2 * time.Millisecond)
+		second, err := namer(f)
+		require.NoError(t, err)
+		assert.Less(t, first, second)
+	}
+}
+
+// TestNamer_DateSharded 验证按日期分目录，并可组合自定义命名函数读取 Filer 元信息。
+func TestNamer_DateSharded(t *testing.T) {
+	dir := t.TempDir()
+	namer := filer.DateNamer("", func(f *filer.Filer) (string, error) {
+		return "avatar-" + string(f.SourceType()) + f.Ext(), nil
+	})
+	f := filer.NewFiler(filer.WithNamer(namer))
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open(filepath.Join("tests", "test.jpg")))
+
+	saved, err := f.SaveTo(dir + "/")
+	require.NoError(t, err)
+	day := time.Now().Format("2006/01/02")
+	assert.Equal(t, filepath.Join(dir, filepath.FromSlash(day), "avatar-local-file.jpg"), saved)
+}
+
+// TestNamer_Escape 验证命名结果中的 ".." 不会跳出目标目录，命名失败时返回错误。
+func TestNamer_Escape(t *testing.T) {
+	dir := t.TempDir()
+	f := filer.NewFiler(filer.WithNamer(func(f *filer.Filer) (string, error) {
+		return "../../escape.txt", nil
+	}))
+	require.NoError(t, f.Open([]byte("data")))
+	saved, err := f.SaveTo(filepath.Join(dir, "out") + "/")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "out", "escape.txt"), saved)
+
+	errNamer := errors.New("namer failed")
+	f = filer.NewFiler(filer.WithNamer(func(f *filer.Filer) (string, error) {
+		return "", errNamer
+	}))
+	require.NoError(t, f.Open([]byte("data")))
+	_, err = f.SaveTo(dir + "/")
+	assert.ErrorIs(t, err, errNamer)
+}
+
+// TestNamer_Storage 验证 SaveToStorage 同样使用命名策略。
+func TestNamer_Storage(t *testing.T) {
+	storage := filer.NewMemoryStorage("")
+	f := filer.NewFiler(filer.WithNamer(filer.HashNamer(8)))
+	require.NoError(t, f.Open([]byte("hello")))
+	key, err := f.SaveToStorage(t.Context(), storage, "files/")
+	require.NoError(t, err)
+	assert.Equal(t, "files/2cf24dba.txt", key)
+}
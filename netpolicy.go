@@ -0,0 +1,213 @@
+package filer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// NetworkPolicy 网络源的访问策略（默认不启用），用于防止以用户输入的 URL 访问内网地址（SSRF）。
+type NetworkPolicy struct {
+	AllowedSchemes  []string // 允许的协议，为空时为 http、https
+	AllowedHosts    []string // 非空时仅允许这些主机，支持 "*.example.com" 匹配子域名
+	DeniedHosts     []string // 禁止的主机，规则同 AllowedHosts，优先于 AllowedHosts
+	BlockPrivateIPs bool     // 禁止连接回环、私有、链路本地、CGNAT、组播等地址（按 DNS 解析后实际连接的 IP 判断），启用后忽略 Transport 的代理设置
+	MaxRedirects    int      // 最大重定向次数，0 为默认的 10 次，< 0 表示不跟随重定向
+}
+
+// NetworkPolicyError 访问被 NetworkPolicy 拒绝
+type NetworkPolicyError struct {
+	URL    string // 被拒绝的地址（URL 或连接地址）
+	Reason string // 拒绝原因
+}
+
+func (e *NetworkPolicyError) Error() string {
+	return fmt.Sprintf("filer: %s is not allowed by network policy, %s", e.URL, e.Reason)
+}
+
+// WithNetworkPolicy 为网络源启用访问策略，重定向同样会被校验。
+func WithNetworkPolicy(policy NetworkPolicy) Option {
+	return func(o *options) {
+		o.networkPolicy = &policy
+		o.policyClient = nil
+	}
+}
+
+// blockedIPPrefixes IsPrivate 等方法未覆盖的保留网段
+var blockedIPPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// isBlockedIP 判断 ip 是否属于非公网地址
+func isBlockedIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, prefix := range blockedIPPrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchHost 判断 host 是否匹配 patterns 中的任一规则
+func matchHost(host string, patterns []string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, p := range patterns {
+		p = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(p)), ".")
+		if suffix, ok := strings.CutPrefix(p, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == p {
+			return true
+		}
+	}
+	return false
+}
+
+// checkURL 校验协议与主机名；主机为 IP 字面量时同时检查地址类型
+func (p *NetworkPolicy) checkURL(u *url.URL) error {
+	schemes := p.AllowedSchemes
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+	if !slices.ContainsFunc(schemes, func(s string) bool { return strings.EqualFold(s, u.Scheme) }) {
+		return &NetworkPolicyError{URL: u.Redacted(), Reason: fmt.Sprintf("scheme %q is not allowed", u.Scheme)}
+	}
+	host := u.Hostname()
+	if matchHost(host, p.DeniedHosts) {
+		return &NetworkPolicyError{URL: u.Redacted(), Reason: fmt.Sprintf("host %q is denied", host)}
+	}
+	if len(p.AllowedHosts) != 0 && !matchHost(host, p.AllowedHosts) {
+		return &NetworkPolicyError{URL: u.Redacted(), Reason: fmt.Sprintf("host %q is not in the allow list", host)}
+	}
+	if p.BlockPrivateIPs {
+		if ip, err := netip.ParseAddr(host); err == nil && isBlockedIP(ip) {
+			return &NetworkPolicyError{URL: u.Redacted(), Reason: fmt.Sprintf("address %s is private", ip)}
+		}
+	}
+	return nil
+}
+
+// checkResolved 解析主机名并检查全部 IP，用于无法接管拨号的自定义 RoundTripper
+func (p *NetworkPolicy) checkResolved(ctx context.Context, u *url.URL) error {
+	if !p.BlockPrivateIPs {
+		return nil
+	}
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, ip := range ips {
+		if isBlockedIP(ip) {
+			return &NetworkPolicyError{URL: u.Redacted(), Reason: fmt.Sprintf("host resolves to private address %s", ip)}
+		}
+	}
+	return nil
+}
+
+// dialControl 在建立连接前检查实际连接的 IP，可防止 DNS 重绑定
+func (p *NetworkPolicy) dialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && isBlockedIP(ip) {
+		return &NetworkPolicyError{URL: address, Reason: fmt.Sprintf("address %s is private", ip)}
+	}
+	return nil
+}
+
+// wrapClient 返回应用了策略的客户端副本：校验每次重定向，并在拨号时检查 IP（此时不经代理直连）
+func (p *NetworkPolicy) wrapClient(c *http.Client) *http.Client {
+	cc := *c
+	maxRedirects := p.MaxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = 10
+	}
+	checkRedirect := c.CheckRedirect
+	cc.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if maxRedirects < 0 || len(via) > maxRedirects {
+			return &NetworkPolicyError{URL: req.URL.Redacted(), Reason: fmt.Sprintf("stopped after %d redirects", len(via))}
+		}
+		if err := p.checkURL(req.URL); err != nil {
+			return err
+		}
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+		return nil
+	}
+
+	var transport *http.Transport
+	switch t := c.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	}
+	if transport != nil && p.BlockPrivateIPs {
+		// 经代理时拨号只能检查代理的 IP，目标主机由代理解析，无法防止解析到内网地址，因此不使用代理
+		transport.Proxy = nil
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   p.dialControl,
+		}
+		transport.DialContext = dialer.DialContext
+		transport.DialTLSContext = nil
+		cc.Transport = transport
+	} else if p.BlockPrivateIPs {
+		// 自定义 RoundTripper 无法接管拨号，退回到请求前解析检查
+		rt := c.Transport
+		cc.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := p.checkResolved(req.Context(), req.URL); err != nil {
+				return nil, err
+			}
+			return rt.RoundTrip(req)
+		})
+	}
+	return &cc
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+// checkNetworkPolicy 请求前校验 URL，未设置策略时直接通过
+func (o *options) checkNetworkPolicy(rawURL string) error {
+	if o.networkPolicy == nil {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	return o.networkPolicy.checkURL(u)
+}
+
+// isNetworkPolicyError 判断 err 是否由策略拒绝导致（此类错误不重试）
+func isNetworkPolicyError(err error) bool {
+	var policyErr *NetworkPolicyError
+	return errors.As(err, &policyErr)
+}
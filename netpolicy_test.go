@@ -0,0 +1,109 @@
+package filer_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assertPolicyError(t *testing.T, err error) {
+	t.Helper()
+	require.Error(t, err)
+	var policyErr *filer.NetworkPolicyError
+	assert.True(t, errors.As(err, &policyErr), "want *NetworkPolicyError, got %v", err)
+}
+
+// TestWithNetworkPolicy_BlockPrivateIPs 验证回环地址（含解析后的域名）被拒绝。
+func TestWithNetworkPolicy_BlockPrivateIPs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("secret"))
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	policy := filer.WithNetworkPolicy(filer.NetworkPolicy{BlockPrivateIPs: true})
+	assertPolicyError(t, filer.NewFiler(policy).Open(srv.URL+"/a.txt"))
+	assertPolicyError(t, filer.NewFiler(policy).Open("http://localhost:"+u.Port()+"/a.txt"))
+	assertPolicyError(t, filer.NewFiler(policy).Open("http://169.254.169.254/latest/meta-data"))
+
+	// 未启用策略时可正常访问
+	f := filer.NewFiler()
+	defer func() { _ = f.Close() }()
+	assert.NoError(t, f.Open(srv.URL+"/a.txt"))
+}
+
+// TestWithNetworkPolicy_BlockPrivateIPsIgnoresProxy 验证启用 BlockPrivateIPs 后不经代理，由拨号检查目标 IP。
+func TestWithNetworkPolicy_BlockPrivateIPsIgnoresProxy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("secret"))
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	proxied := false
+	client := &http.Client{Transport: &http.Transport{Proxy: func(*http.Request) (*url.URL, error) {
+		proxied = true
+		return nil, nil
+	}}}
+	f := filer.NewFiler(filer.WithHTTPClient(client), filer.WithNetworkPolicy(filer.NetworkPolicy{BlockPrivateIPs: true}))
+	assertPolicyError(t, f.Open("http://localhost:"+u.Port()+"/a.txt"))
+	assert.False(t, proxied)
+}
+
+// TestWithNetworkPolicy_Hosts 验证主机允许/禁止列表，以及重定向到不允许的主机。
+func TestWithNetworkPolicy_Hosts(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)+"/a.txt", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	allow := filer.WithNetworkPolicy(filer.NetworkPolicy{AllowedHosts: []string{"127.0.0.1"}})
+	f := filer.NewFiler(allow)
+	defer func() { _ = f.Close() }()
+	assert.NoError(t, f.Open(srv.URL+"/a.txt"))
+	assertPolicyError(t, filer.NewFiler(allow).Open(srv.URL+"/redirect"))
+
+	deny := filer.WithNetworkPolicy(filer.NetworkPolicy{DeniedHosts: []string{"127.0.0.1", "*.example.com"}})
+	assertPolicyError(t, filer.NewFiler(deny).Open(srv.URL+"/a.txt"))
+	assertPolicyError(t, filer.NewFiler(deny).Open("https://cdn.example.com/a.txt"))
+}
+
+// TestWithNetworkPolicy_SchemesAndRedirects 验证协议限制与最大重定向次数。
+func TestWithNetworkPolicy_SchemesAndRedirects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/loop" {
+			http.Redirect(w, r, "/loop", http.StatusFound)
+			return
+		}
+		if r.URL.Path == "/once" {
+			http.Redirect(w, r, "/a.txt", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	httpsOnly := filer.WithNetworkPolicy(filer.NetworkPolicy{AllowedSchemes: []string{"https"}})
+	assertPolicyError(t, filer.NewFiler(httpsOnly).Open(srv.URL+"/a.txt"))
+
+	limited := filer.WithNetworkPolicy(filer.NetworkPolicy{MaxRedirects: 3})
+	assertPolicyError(t, filer.NewFiler(limited).Open(srv.URL+"/loop"))
+	f := filer.NewFiler(limited)
+	defer func() { _ = f.Close() }()
+	assert.NoError(t, f.Open(srv.URL+"/once"))
+
+	noRedirect := filer.WithNetworkPolicy(filer.NetworkPolicy{MaxRedirects: -1})
+	assertPolicyError(t, filer.NewFiler(noRedirect).Open(srv.URL+"/once"))
+}
@@ -0,0 +1,110 @@
+package filer
+
+import (
+	"net/http"
+	"time"
+)
+
+// options Filer 的可选配置，由 NewFiler 的 Option 设置，Open 时不会被重置。
+type options struct {
+	httpClient   *http.Client   // 拉取网络文件使用的客户端，为空时使用 defaultHTTPClient
+	header       http.Header    // 网络请求附加的请求头
+	cookies      []*http.Cookie // 网络请求附加的 Cookie
+	timeout      time.Duration  // 单次网络请求超时（含读取响应体），<= 0 时沿用客户端自身的设置
+	retry        RetryPolicy    // 网络请求重试与断点续传策略
+	maxSize      int64          // 文件大小上限（字节），<= 0 表示不限制
+	syncDir      bool           // 原子保存后是否 fsync 所在目录
+	conflict     ConflictPolicy // SaveTo 目标文件已存在时的处理方式
+	namer        Namer          // 目标为目录时的文件命名策略
+	hashAlgos    []HashAlgo     // 完整读取内容时顺带计算的摘要
+	checksums    []Checksum     // 完整读取内容后校验的摘要
+	urlBuilder   *URLBuilder    // 生成保存后 Uri() 的 URLBuilder，为空时使用 pathURI
+	quota        *Quota         // SaveTo 的配额与可用空间限制，为空时不限制
+	policy       *Policy        // Open 与 SaveTo 时应用的校验策略，为空时不校验
+	strictExt    bool           // 扩展名与内容不一致时拒绝
+	mimeRegistry *MIMERegistry  // MIME 类型与扩展名映射表，为空时使用 DefaultMIMERegistry
+	imageLimits  *ImageLimits   // 图像解码前的尺寸上限，为空时使用 DefaultImageLimits
+
+	headerChecksum bool // 是否按网络响应头中的摘要校验内容
+
+	networkPolicy *NetworkPolicy // 网络访问策略，为空时不限制
+	policyClient  *http.Client   // 应用了 networkPolicy 的客户端，首次使用时创建
+}
+
+// Option 配置 Filer 的函数式选项
+type Option func(*options)
+
+// WithHTTPClient 指定拉取网络文件使用的 http.Client，可借此定制代理、Transport、TLS 等。
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+		o.policyClient = nil
+	}
+}
+
+// WithHeader 为网络请求追加请求头，如 Authorization、Referer 等；可多次调用。
+func WithHeader(key, value string) Option {
+	return func(o *options) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Add(key, value)
+	}
+}
+
+// WithUserAgent 设置网络请求的 User-Agent
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Set("User-Agent", userAgent)
+	}
+}
+
+// WithCookies 为网络请求附加 Cookie
+func WithCookies(cookies ...*http.Cookie) Option {
+	return func(o *options) {
+		o.cookies = append(o.cookies, cookies...)
+	}
+}
+
+// WithTimeout 设置单次网络请求的超时时间（包含读取响应体），覆盖客户端自身的 Timeout。
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+		o.policyClient = nil
+	}
+}
+
+// client 返回网络请求使用的 http.Client
+func (o *options) client() *http.Client {
+	c := o.httpClient
+	if c == nil {
+		c = defaultHTTPClient
+	}
+	if o.timeout > 0 {
+		cc := *c
+		cc.Timeout = o.timeout
+		c = &cc
+	}
+	if o.networkPolicy != nil {
+		if o.policyClient == nil {
+			o.policyClient = o.networkPolicy.wrapClient(c)
+		}
+		c = o.policyClient
+	}
+	return c
+}
+
+// applyRequest 将请求头与 Cookie 写入 req
+func (o *options) applyRequest(req *http.Request) {
+	for k, values := range o.header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	for _, c := range o.cookies {
+		req.AddCookie(c)
+	}
+}
@@ -0,0 +1,82 @@
+package filer_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithHeaderAndCookies 验证请求头、User-Agent 与 Cookie 被附加到网络请求。
+func TestWithHeaderAndCookies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" || r.UserAgent() != "filer-test" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if c, err := r.Cookie("session"); err != nil || c.Value != "abc" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	f := filer.NewFiler(
+		filer.WithHeader("Authorization", "Bearer token"),
+		filer.WithUserAgent("filer-test"),
+		filer.WithCookies(&http.Cookie{Name: "session", Value: "abc"}),
+	)
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open(srv.URL+"/a.txt"))
+	b, err := f.Body()
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(b))
+
+	// 未带选项时被拒绝
+	f2 := filer.NewFiler()
+	assert.Error(t, f2.Open(srv.URL+"/a.txt"))
+}
+
+// TestWithHTTPClient 验证使用自定义客户端发起请求。
+func TestWithHTTPClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	var used bool
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		used = true
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+	f := filer.NewFiler(filer.WithHTTPClient(client))
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open(srv.URL+"/a.txt"))
+	assert.True(t, used)
+}
+
+// TestWithTimeout 验证单次请求超时生效。
+func TestWithTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	f := filer.NewFiler(filer.WithTimeout(50 * time.Millisecond))
+	defer func() { _ = f.Close() }()
+	assert.Error(t, f.Open(srv.URL+"/slow.txt"))
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
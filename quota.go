@@ -0,0 +1,218 @@
+package filer
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// UsageTracker 目录用量统计，Quota 据此判断是否超出配额。
+// 实现可以基于数据库、Redis 等，需可并发使用。
+type UsageTracker interface {
+	// Usage 返回 dir 当前已用字节数
+	Usage(dir string) (int64, error)
+	// Add 在保存成功后调整 dir 的用量，覆盖已有文件时 delta 为新旧文件大小之差
+	Add(dir string, delta int64) error
+}
+
+// Quota SaveTo 的配额与磁盘可用空间限制
+type Quota struct {
+	Limit          int64                        // 每个目录的用量上限（字节），<= 0 表示不限制
+	Tracker        UsageTracker                 // 用量统计，为空时使用 DirUsage
+	Dir            func(filename string) string // 文件计入的目录，为空时为文件所在目录；多租户时可映射到租户根目录
+	CheckFreeSpace bool                         // 是否检查文件系统可用空间（statfs），不支持的平台忽略
+	MinFreeSpace   int64                        // 检查可用空间时，保存后至少保留的字节数
+}
+
+// WithQuota 设置写入本地磁盘的配额：大小已知时写入前检查，未知时在写入过程中检查，超出时返回 *QuotaError 且不留下文件。
+// 适用于 SaveTo、SaveToContentAddressed（计入 dir 本身）与 Imager.SaveTo；SaveToStorage 不受限制，
+// 存储后端（包括 LocalStorage）的容量由后端自行管理。
+func WithQuota(quota Quota) Option {
+	return func(o *options) {
+		o.quota = &quota
+	}
+}
+
+// QuotaError 超出目录配额或文件系统可用空间不足
+type QuotaError struct {
+	Dir       string // 计入配额的目录
+	Limit     int64  // 配额上限；FreeSpace 为 true 时为可用空间减去 MinFreeSpace
+	Used      int64  // 已用量；FreeSpace 为 true 时为 0
+	Size      int64  // 本次写入的大小，写入过程中超限时为 -1
+	FreeSpace bool   // true 表示文件系统可用空间不足
+}
+
+func (e *QuotaError) Error() string {
+	size := "unknown size"
+	if e.Size >= 0 {
+		size = fmt.Sprintf("%d bytes", e.Size)
+	}
+	if e.FreeSpace {
+		return fmt.Sprintf("filer: insufficient free space in %s, %d bytes available, writing %s", e.Dir, e.Limit, size)
+	}
+	return fmt.Sprintf("filer: quota exceeded for %s, %d of %d bytes used, writing %s", e.Dir, e.Used, e.Limit, size)
+}
+
+// Is 使 errors.Is(err, ErrQuotaExceeded) 成立
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// DirUsage 遍历目录累加其中（含子目录）普通文件的大小作为用量，Add 为空操作；结果准确但目录很大时较慢
+type DirUsage struct{}
+
+// Usage 返回 dir 下全部普通文件的大小之和，目录不存在时为 0
+func (DirUsage) Usage(dir string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
+
+// Add 用量由 Usage 实时计算，无需记录
+func (DirUsage) Add(string, int64) error {
+	return nil
+}
+
+// MemoryUsage 在内存中记录各目录的用量，适合测试或由外部数据初始化后使用；可安全地并发使用
+type MemoryUsage struct {
+	mu    sync.Mutex
+	usage map[string]int64
+}
+
+// NewMemoryUsage 创建 MemoryUsage
+func NewMemoryUsage() *MemoryUsage {
+	return &MemoryUsage{usage: make(map[string]int64)}
+}
+
+// Usage 返回 dir 的用量
+func (u *MemoryUsage) Usage(dir string) (int64, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.usage[filepath.Clean(dir)], nil
+}
+
+// Add 调整 dir 的用量
+func (u *MemoryUsage) Add(dir string, delta int64) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.usage[filepath.Clean(dir)] += delta
+	return nil
+}
+
+// Set 设置 dir 的用量，用于初始化
+func (u *MemoryUsage) Set(dir string, usage int64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.usage[filepath.Clean(dir)] = usage
+}
+
+// quotaState 一次 SaveTo 的配额检查结果
+type quotaState struct {
+	tracker   UsageTracker
+	dir       string
+	replaced  int64       // 覆盖的已有文件大小
+	remaining int64       // 允许写入的字节数，< 0 表示不限制
+	err       *QuotaError // 写入超出 remaining 时返回的错误
+}
+
+// checkQuota 写入 filename 前按 Size() 检查配额与可用空间，未设置 WithQuota 时返回 nil
+func (f *Filer) checkQuota(filename string) (*quotaState, error) {
+	size, err := f.sizeOf()
+	if err != nil {
+		size = -1
+	}
+	return f.checkQuotaSize(filename, size, f.opts.conflict == ConflictOverwrite)
+}
+
+// checkQuotaSize 写入 size 字节（< 0 表示未知）到 filename 前检查配额与可用空间，
+// overwrite 为 true 时被覆盖的已有文件大小不计入用量
+func (f *Filer) checkQuotaSize(filename string, size int64, overwrite bool) (*quotaState, error) {
+	q := f.opts.quota
+	if q == nil {
+		return nil, nil
+	}
+	st := &quotaState{tracker: q.Tracker, dir: filepath.Dir(filename), remaining: -1}
+	if st.tracker == nil {
+		st.tracker = DirUsage{}
+	}
+	if q.Dir != nil {
+		st.dir = filepath.Clean(q.Dir(filename))
+	}
+	if overwrite {
+		if info, err := os.Stat(filename); err == nil && info.Mode().IsRegular() {
+			st.replaced = info.Size()
+		}
+	}
+
+	if q.Limit > 0 {
+		used, err := st.tracker.Usage(st.dir)
+		if err != nil {
+			return nil, fmt.Errorf("get %s usage failed, %w", st.dir, err)
+		}
+		st.remaining = q.Limit - used + st.replaced
+		st.err = &QuotaError{Dir: st.dir, Limit: q.Limit, Used: used, Size: -1}
+		if size >= 0 && size > st.remaining {
+			return nil, &QuotaError{Dir: st.dir, Limit: q.Limit, Used: used, Size: size}
+		}
+	}
+	if q.CheckFreeSpace {
+		// 文件所在目录此时已创建，可用空间按其所在文件系统计算
+		available, ok, err := diskFree(filepath.Dir(filename))
+		if err != nil {
+			return nil, fmt.Errorf("get %s free space failed, %w", filepath.Dir(filename), err)
+		}
+		if ok {
+			free := available - q.MinFreeSpace
+			if size >= 0 && size > free {
+				return nil, &QuotaError{Dir: filepath.Dir(filename), Limit: free, Size: size, FreeSpace: true}
+			}
+			if st.remaining < 0 || free < st.remaining {
+				st.remaining = free
+				st.err = &QuotaError{Dir: filepath.Dir(filename), Limit: free, Size: -1, FreeSpace: true}
+			}
+		}
+	}
+	return st, nil
+}
+
+// quotaWriter 统计写入字节数，超出 remaining 时返回配额错误
+type quotaWriter struct {
+	w       io.Writer
+	st      *quotaState
+	written int64
+}
+
+func (w *quotaWriter) Write(p []byte) (int, error) {
+	if w.st.remaining >= 0 && w.written+int64(len(p)) > w.st.remaining {
+		return 0, w.st.err
+	}
+	n, err := w.w.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+// commit 保存成功后更新用量
+func (w *quotaWriter) commit() error {
+	if err := w.st.tracker.Add(w.st.dir, w.written-w.st.replaced); err != nil {
+		return fmt.Errorf("update %s usage failed, %w", w.st.dir, err)
+	}
+	return nil
+}
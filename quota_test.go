@@ -0,0 +1,149 @@
+package filer_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQuota_KnownSize 验证大小已知时写入前按目录用量拒绝，成功保存后累加用量。
+func TestQuota_KnownSize(t *testing.T) {
+	dir := t.TempDir()
+	usage := filer.NewMemoryUsage()
+	usage.Set(dir, 90)
+	opt := filer.WithQuota(filer.Quota{Limit: 100, Tracker: usage})
+
+	f := filer.NewFiler(opt)
+	require.NoError(t, f.Open(bytes.Repeat([]byte("a"), 10)))
+	_, err := f.SaveTo(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	used, _ := usage.Usage(dir)
+	assert.Equal(t, int64(100), used)
+
+	f = filer.NewFiler(opt)
+	require.NoError(t, f.Open([]byte("b")))
+	_, err = f.SaveTo(filepath.Join(dir, "b.txt"))
+	var quotaErr *filer.QuotaError
+	require.True(t, errors.As(err, &quotaErr))
+	assert.ErrorIs(t, err, filer.ErrQuotaExceeded)
+	assert.Equal(t, filer.QuotaError{Dir: dir, Limit: 100, Used: 100, Size: 1}, *quotaErr)
+	_, err = os.Stat(filepath.Join(dir, "b.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	// 覆盖已有文件时按新旧大小之差计算
+	f = filer.NewFiler(opt)
+	require.NoError(t, f.Open(bytes.Repeat([]byte("c"), 5)))
+	_, err = f.SaveTo(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	used, _ = usage.Usage(dir)
+	assert.Equal(t, int64(95), used)
+}
+
+// TestQuota_Streaming 验证大小未知时在写入过程中拒绝，且不留下文件。
+func TestQuota_Streaming(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "existing.bin"), make([]byte, 64), 0644))
+
+	f := filer.NewFiler(filer.WithQuota(filer.Quota{Limit: 100}))
+	require.NoError(t, f.Open(struct{ io.Reader }{bytes.NewReader(make([]byte, 50))}))
+	_, err := f.SaveTo(filepath.Join(dir, "stream.bin"))
+	var quotaErr *filer.QuotaError
+	require.True(t, errors.As(err, &quotaErr))
+	assert.Equal(t, int64(-1), quotaErr.Size)
+	assert.Equal(t, int64(64), quotaErr.Used)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	f = filer.NewFiler(filer.WithQuota(filer.Quota{Limit: 100}))
+	require.NoError(t, f.Open(struct{ io.Reader }{bytes.NewReader(make([]byte, 36))}))
+	_, err = f.SaveTo(filepath.Join(dir, "stream.bin"))
+	assert.NoError(t, err)
+}
+
+// TestQuota_Dir 验证可将文件映射到租户根目录统一计算配额。
+func TestQuota_Dir(t *testing.T) {
+	root := t.TempDir()
+	tenant := filepath.Join(root, "tenant-1")
+	require.NoError(t, os.MkdirAll(filepath.Join(tenant, "old"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tenant, "old", "a.bin"), make([]byte, 8), 0644))
+
+	quota := filer.Quota{
+		Limit: 10,
+		Dir:   func(string) string { return tenant },
+	}
+	f := filer.NewFiler(filer.WithQuota(quota))
+	require.NoError(t, f.Open(make([]byte, 4)))
+	_, err := f.SaveTo(filepath.Join(tenant, "2026", "10", "b.bin"))
+	var quotaErr *filer.QuotaError
+	require.True(t, errors.As(err, &quotaErr))
+	assert.Equal(t, tenant, quotaErr.Dir)
+}
+
+// TestQuota_FreeSpace 验证按文件系统可用空间拒绝写入。
+func TestQuota_FreeSpace(t *testing.T) {
+	dir := t.TempDir()
+	f := filer.NewFiler(filer.WithQuota(filer.Quota{CheckFreeSpace: true, MinFreeSpace: 1 << 62}))
+	require.NoError(t, f.Open([]byte("data")))
+	_, err := f.SaveTo(filepath.Join(dir, "a.txt"))
+	var quotaErr *filer.QuotaError
+	require.True(t, errors.As(err, &quotaErr))
+	assert.True(t, quotaErr.FreeSpace)
+
+	f = filer.NewFiler(filer.WithQuota(filer.Quota{CheckFreeSpace: true}))
+	require.NoError(t, f.Open([]byte("data")))
+	_, err = f.SaveTo(filepath.Join(dir, "a.txt"))
+	assert.NoError(t, err)
+}
+
+// TestQuota_OtherWrites 验证 SaveToContentAddressed 与 Imager.SaveTo 同样受配额限制，重复内容不计入用量。
+func TestQuota_OtherWrites(t *testing.T) {
+	dir := t.TempDir()
+	usage := filer.NewMemoryUsage()
+	opt := filer.WithQuota(filer.Quota{Limit: 100, Tracker: usage})
+
+	f := filer.NewFiler(opt)
+	require.NoError(t, f.Open(bytes.Repeat([]byte("a"), 60)))
+	_, err := f.SaveToContentAddressed(dir)
+	require.NoError(t, err)
+	used, _ := usage.Usage(dir)
+	assert.Equal(t, int64(60), used)
+
+	// 已存在的内容不写入，也不受配额限制
+	_, err = f.SaveToContentAddressed(dir)
+	require.NoError(t, err)
+	used, _ = usage.Usage(dir)
+	assert.Equal(t, int64(60), used)
+
+	for _, content := range []io.Reader{bytes.NewReader(make([]byte, 50)), struct{ io.Reader }{bytes.NewReader(make([]byte, 50))}} {
+		f = filer.NewFiler(opt)
+		require.NoError(t, f.Open(content))
+		_, err = f.SaveToContentAddressed(dir)
+		assert.ErrorIs(t, err, filer.ErrQuotaExceeded)
+	}
+
+	png := pngFixture(64, 64)
+	usage.Set(dir, int64(100-len(png)+1))
+	f = filer.NewFiler(opt)
+	require.NoError(t, f.Open(png))
+	img, err := f.Imager()
+	require.NoError(t, err)
+	assert.ErrorIs(t, img.SaveTo(filepath.Join(dir, "avatar.png")), filer.ErrQuotaExceeded)
+	_, err = os.Stat(filepath.Join(dir, "avatar.png"))
+	assert.True(t, os.IsNotExist(err))
+
+	usage.Set(dir, 0)
+	require.NoError(t, img.Resize(8, 8))
+	require.NoError(t, img.SaveTo(filepath.Join(dir, "avatar.png")))
+	info, err := os.Stat(filepath.Join(dir, "avatar.png"))
+	require.NoError(t, err)
+	used, _ = usage.Usage(dir)
+	assert.Equal(t, info.Size(), used)
+}
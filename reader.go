@@ -0,0 +1,95 @@
+package filer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+)
+
+// sizedReaderAt 带 Size 的 io.ReaderAt，如 io.SectionReader、bytes.Reader、strings.Reader
+type sizedReaderAt interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// readSeekNopCloser 为不带 Close 的 io.ReadSeeker 补上空 Close，同时保留 Seeker 能力
+type readSeekNopCloser struct {
+	io.ReadSeeker
+}
+
+func (r readSeekNopCloser) Close() error { return nil }
+
+// OpenFS 打开 fsys 中的 name 文件（如 embed.FS、os.DirFS），文件名与大小取自 fs.FileInfo
+func (f *Filer) OpenFS(fsys fs.FS, name string) error {
+	return f.openWith(context.Background(), func() error {
+		file, err := fsys.Open(name)
+		if err != nil {
+			f.typ = SourceFSFile
+			f.path = name
+			return err
+		}
+		if err = f.openFSFile(file); err != nil {
+			_ = file.Close()
+			return err
+		}
+		f.path = name
+		return nil
+	})
+}
+
+// openFSFile 打开 fs.File，文件名、扩展名与大小取自 Stat
+func (f *Filer) openFSFile(file fs.File) error {
+	f.typ = SourceFSFile
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%w, %s is a directory", ErrUnsupportedSource, info.Name())
+	}
+	f.name = info.Name()
+	f.possibleExt = path.Ext(f.name)
+	f.size = info.Size()
+	f.readCloser = file
+	return nil
+}
+
+// openReader 打开通用 io.Reader：可 Seek 的流保留 Seeker 能力，带 Size 的 io.ReaderAt 转为 io.SectionReader，
+// 其余流只能顺序读取一次（大小未知，IsImage、Info 等需要时会缓冲为内存流）。
+// 若 r 实现了 io.Closer，Close 时会一并关闭。
+func (f *Filer) openReader(r io.Reader) error {
+	f.typ = SourceReader
+	f.size = -1
+	closer, _ := r.(io.Closer)
+	var rs io.ReadSeeker
+	switch v := r.(type) {
+	case io.ReadSeeker:
+		rs = v
+	case sizedReaderAt:
+		rs = io.NewSectionReader(v, 0, v.Size())
+	}
+	switch {
+	case rs != nil && closer != nil:
+		f.readCloser = struct {
+			io.ReadSeeker
+			io.Closer
+		}{rs, closer}
+	case rs != nil:
+		f.readCloser = readSeekNopCloser{rs}
+	case closer != nil:
+		f.readCloser = struct {
+			io.Reader
+			io.Closer
+		}{r, closer}
+	default:
+		f.readCloser = io.NopCloser(r)
+	}
+	return nil
+}
+
+// openReaderAt 打开只实现了 io.ReaderAt 与 Size 的数据源
+func (f *Filer) openReaderAt(r sizedReaderAt) error {
+	return f.openReader(io.NewSectionReader(r, 0, r.Size()))
+}
@@ -0,0 +1,108 @@
+package filer_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"embed"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//go:embed tests/test.txt tests/test.jpg
+var testFS embed.FS
+
+// TestOpen_Readers 验证通用 io.Reader 类数据源的读取与大小。
+func TestOpen_Readers(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write([]byte("hello"))
+	_ = zw.Close()
+	zr, err := gzip.NewReader(&gz)
+	require.NoError(t, err)
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, _ = pw.Write([]byte("hello"))
+		_ = pw.Close()
+	}()
+
+	tests := []struct {
+		name string
+		src  any
+	}{
+		{"bytes.Buffer", bytes.NewBufferString("hello")},
+		{"strings.Reader", strings.NewReader("hello")},
+		{"section reader", io.NewSectionReader(strings.NewReader("xxhello"), 2, 5)},
+		{"gzip.Reader", zr},
+		{"pipe", pr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := filer.NewFiler()
+			defer func() { _ = f.Close() }()
+			require.NoError(t, f.Open(tt.src))
+			assert.Equal(t, filer.SourceReader, f.SourceType())
+			info, err := f.Info()
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), info.Size.Int64)
+			b, err := f.Body()
+			require.NoError(t, err)
+			assert.Equal(t, "hello", string(b))
+		})
+	}
+}
+
+// TestOpen_ReaderAtOnly 验证只实现 ReadAt 与 Size 的数据源。
+func TestOpen_ReaderAtOnly(t *testing.T) {
+	f := filer.NewFiler()
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open(readerAtOnly{strings.NewReader("hello")}))
+	size, err := f.Size()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), size)
+	b, err := f.Body()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+}
+
+type readerAtOnly struct {
+	r *strings.Reader
+}
+
+func (r readerAtOnly) ReadAt(p []byte, off int64) (int, error) { return r.r.ReadAt(p, off) }
+func (r readerAtOnly) Size() int64                             { return r.r.Size() }
+
+// TestOpenFS 验证 OpenFS 与 fs.File 输入的文件名、大小与内容。
+func TestOpenFS(t *testing.T) {
+	f := filer.NewFiler()
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.OpenFS(testFS, "tests/test.txt"))
+	assert.Equal(t, filer.SourceFSFile, f.SourceType())
+	assert.Equal(t, "test.txt", f.Name())
+	assert.Equal(t, ".txt", f.Ext())
+	size, err := f.Size()
+	require.NoError(t, err)
+	assert.Equal(t, int64(13), size)
+
+	require.NoError(t, f.OpenFS(testFS, "tests/test.jpg"))
+	assert.True(t, f.IsImage())
+
+	assert.ErrorIs(t, f.OpenFS(testFS, "tests/missing.txt"), fs.ErrNotExist)
+	assert.ErrorIs(t, f.OpenFS(testFS, "tests"), filer.ErrUnsupportedSource)
+
+	mapFS := fstest.MapFS{"docs/readme.md": {Data: []byte("# hi")}}
+	file, err := mapFS.Open("docs/readme.md")
+	require.NoError(t, err)
+	require.NoError(t, f.Open(file))
+	assert.Equal(t, "readme.md", f.Name())
+	b, err := f.Body()
+	require.NoError(t, err)
+	assert.Equal(t, "# hi", string(b))
+}
@@ -0,0 +1,246 @@
+package filer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy 网络请求的重试策略。
+// 首次请求在连接错误、5xx、429 时按指数退避重试；下载中途连接中断时，若服务端声明了 Accept-Ranges: bytes
+// 且提供 ETag 或 Last-Modified，则使用 Range + If-Range 从断点续传，远端文件发生变化时放弃续传并返回错误。
+type RetryPolicy struct {
+	MaxRetries     int           // 最大重试次数（不含首次请求），<= 0 表示不重试
+	InitialBackoff time.Duration // 首次重试前的等待时间，默认 200ms
+	MaxBackoff     time.Duration // 单次等待上限，默认 10s
+	Multiplier     float64       // 退避倍数，默认 2
+}
+
+// WithRetry 为网络源设置重试与断点续传策略
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = policy
+	}
+}
+
+// backoff 返回第 attempt 次（从 1 开始）重试前的等待时间
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	if d <= 0 {
+		d = 200 * time.Millisecond
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 10 * time.Second
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	for i := 1; i < attempt && d < maxBackoff; i++ {
+		d = time.Duration(float64(d) * multiplier)
+	}
+	return min(d, maxBackoff)
+}
+
+// sleepContext 等待 d，ctx 取消时提前返回 ctx.Err()
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// retryableStatus 是否为可重试的响应状态
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// retryAfter 解析 Retry-After（秒数），无法解析时返回 0
+func retryAfter(resp *http.Response) time.Duration {
+	if seconds, err := strconv.Atoi(strings.TrimSpace(resp.Header.Get("Retry-After"))); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
+
+// newRequest 创建附带选项中请求头与 Cookie 的 GET 请求
+func (f *Filer) newRequest(ctx context.Context, rawURL string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	f.opts.applyRequest(req)
+	return req, nil
+}
+
+// fetch 拉取网络文件，按 RetryPolicy 重试，成功时返回 200 响应；响应体在条件允许时支持断点续传。
+func (f *Filer) fetch(ctx context.Context, rawURL string) (*http.Response, error) {
+	if err := f.opts.checkNetworkPolicy(rawURL); err != nil {
+		return nil, err
+	}
+	client := f.opts.client()
+	policy := f.opts.retry
+	for attempt := 0; ; attempt++ {
+		req, err := f.newRequest(ctx, rawURL)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		var wait time.Duration
+		if err != nil {
+			if ctx.Err() != nil || isNetworkPolicyError(err) || attempt >= policy.MaxRetries {
+				return nil, err
+			}
+		} else if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			if !retryableStatus(resp.StatusCode) || attempt >= policy.MaxRetries {
+				return nil, &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+			}
+			wait = retryAfter(resp)
+		} else {
+			if policy.MaxRetries > 0 {
+				resp.Body = newResumableBody(ctx, f, rawURL, resp)
+			}
+			return resp, nil
+		}
+
+		if wait <= 0 {
+			wait = policy.backoff(attempt + 1)
+		}
+		if err = sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// resumableBody 在读取中断时通过 Range 请求从断点继续读取的响应体
+type resumableBody struct {
+	ctx          context.Context
+	filer        *Filer
+	url          string
+	body         io.ReadCloser
+	etag         string // 强 ETag，用于 If-Range 与校验
+	lastModified string
+	offset       int64 // 已读取的字节数
+	retries      int   // 已用掉的续传次数
+}
+
+// newResumableBody 服务端支持 Range 且有可用校验值时包装响应体，否则原样返回
+func newResumableBody(ctx context.Context, f *Filer, rawURL string, resp *http.Response) io.ReadCloser {
+	if !strings.EqualFold(strings.TrimSpace(resp.Header.Get("Accept-Ranges")), "bytes") {
+		return resp.Body
+	}
+	etag := resp.Header.Get("ETag")
+	if strings.HasPrefix(etag, "W/") {
+		// 弱 ETag 不能用于 If-Range
+		etag = ""
+	}
+	lastModified := resp.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return resp.Body
+	}
+	return &resumableBody{
+		ctx:          ctx,
+		filer:        f,
+		url:          rawURL,
+		body:         resp.Body,
+		etag:         etag,
+		lastModified: lastModified,
+	}
+}
+
+func (b *resumableBody) Read(p []byte) (int, error) {
+	for {
+		n, err := b.body.Read(p)
+		b.offset += int64(n)
+		if err == nil || err == io.EOF || b.ctx.Err() != nil || b.retries >= b.filer.opts.retry.MaxRetries {
+			return n, err
+		}
+		if rerr := b.resume(err); rerr != nil {
+			return n, rerr
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// resume 等待退避时间后以 Range 请求从 offset 处继续下载，cause 为中断读取的错误；
+// 重试次数用尽时返回最后一次续传的错误
+func (b *resumableBody) resume(cause error) error {
+	lastErr := cause
+	for b.retries < b.filer.opts.retry.MaxRetries {
+		b.retries++
+		if err := sleepContext(b.ctx, b.filer.opts.retry.backoff(b.retries)); err != nil {
+			return err
+		}
+		req, err := b.filer.newRequest(b.ctx, b.url)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", b.offset))
+		if b.etag != "" {
+			req.Header.Set("If-Range", b.etag)
+		} else {
+			req.Header.Set("If-Range", b.lastModified)
+		}
+		resp, err := b.filer.opts.client().Do(req)
+		if err != nil {
+			if isNetworkPolicyError(err) {
+				return err
+			}
+			lastErr = err
+			continue
+		}
+		switch {
+		case resp.StatusCode == http.StatusPartialContent:
+			if !b.sameRepresentation(resp) {
+				_ = resp.Body.Close()
+				return ErrRemoteChanged
+			}
+			_ = b.body.Close()
+			b.body = resp.Body
+			return nil
+		case resp.StatusCode == http.StatusOK:
+			// If-Range 不匹配时服务端返回完整的新内容
+			_ = resp.Body.Close()
+			return ErrRemoteChanged
+		default:
+			_ = resp.Body.Close()
+			lastErr = &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+			if !retryableStatus(resp.StatusCode) {
+				return lastErr
+			}
+		}
+	}
+	return lastErr
+}
+
+// sameRepresentation 校验 206 响应的起始位置与 ETag/Last-Modified 是否与首次响应一致
+func (b *resumableBody) sameRepresentation(resp *http.Response) bool {
+	cr := resp.Header.Get("Content-Range")
+	var start int64
+	if _, err := fmt.Sscanf(cr, "bytes %d-", &start); err != nil || start != b.offset {
+		return false
+	}
+	if b.etag != "" {
+		return resp.Header.Get("ETag") == b.etag
+	}
+	if lm := resp.Header.Get("Last-Modified"); lm != "" && lm != b.lastModified {
+		return false
+	}
+	return true
+}
+
+func (b *resumableBody) Close() error {
+	return b.body.Close()
+}
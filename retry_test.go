@@ -0,0 +1,159 @@
+package filer_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fastRetry = filer.RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+// TestWithRetry_ServerErrors 验证 5xx/429 后按策略重试直至成功。
+func TestWithRetry_ServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer srv.Close()
+
+	f := filer.NewFiler(filer.WithRetry(fastRetry))
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open(srv.URL+"/a.txt"))
+	b, err := f.Body()
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(b))
+	assert.Equal(t, int32(3), calls.Load())
+
+	// 不重试时首个 503 即失败
+	calls.Store(0)
+	assert.Error(t, filer.NewFiler().Open(srv.URL+"/a.txt"))
+}
+
+// TestWithRetry_NotRetryableStatus 验证 4xx（429 除外）不重试。
+func TestWithRetry_NotRetryableStatus(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	f := filer.NewFiler(filer.WithRetry(fastRetry))
+	assert.Error(t, f.Open(srv.URL+"/a.txt"))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+// droppingServer 首次请求只写出一半内容后断开连接，之后的 Range 请求返回剩余部分。
+// etag 返回当前 ETag，便于模拟远端文件在续传前发生变化。
+func droppingServer(t *testing.T, content []byte, etag func() string) *httptest.Server {
+	return httptest.NewServer(droppingHandler(t, content, etag))
+}
+
+// droppingHandler droppingServer 使用的处理函数
+func droppingHandler(t *testing.T, content []byte, etag func() string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Accept-Ranges", "bytes")
+		w.Header().Set("ETag", etag())
+		if rg := r.Header.Get("Range"); rg != "" {
+			if r.Header.Get("If-Range") != etag() {
+				w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+				_, _ = w.Write(content)
+				return
+			}
+			var start int
+			_, _ = fmt.Sscanf(rg, "bytes=%d-", &start)
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+			w.Header().Set("Content-Length", fmt.Sprint(len(content)-start))
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write(content[start:])
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		_, _ = w.Write(content[:len(content)/2])
+		w.(http.Flusher).Flush()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_ = conn.Close()
+	}
+}
+
+// TestWithRetry_ResumeWithRange 验证连接中断后通过 Range 续传得到完整内容。
+func TestWithRetry_ResumeWithRange(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	srv := droppingServer(t, content, func() string { return `"v1"` })
+	defer srv.Close()
+
+	f := filer.NewFiler(filer.WithRetry(fastRetry))
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open(srv.URL+"/big.txt"))
+	filename := filepath.Join(t.TempDir(), "big.txt")
+	_, err := f.SaveTo(filename)
+	require.NoError(t, err)
+	got, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+}
+
+// TestWithRetry_ResumeRemoteChanged 验证远端 ETag 变化后不会拼接内容。
+func TestWithRetry_ResumeRemoteChanged(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	var calls atomic.Int32
+	srv := droppingServer(t, content, func() string {
+		if calls.Add(1) > 1 {
+			return `"v2"`
+		}
+		return `"v1"`
+	})
+	defer srv.Close()
+
+	f := filer.NewFiler(filer.WithRetry(fastRetry))
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open(srv.URL+"/big.txt"))
+	_, err := f.SaveTo(filepath.Join(t.TempDir(), "big.txt"))
+	assert.ErrorIs(t, err, filer.ErrRemoteChanged)
+}
+
+// TestWithRetry_ResumeExhausted 验证续传次数用尽时返回最后一次续传的错误。
+func TestWithRetry_ResumeExhausted(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	var resumes atomic.Int32
+	dropping := droppingHandler(t, content, func() string { return `"v1"` })
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			resumes.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		dropping(w, r)
+	}))
+	defer srv.Close()
+
+	f := filer.NewFiler(filer.WithRetry(fastRetry))
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open(srv.URL+"/big.txt"))
+	_, err := f.SaveTo(filepath.Join(t.TempDir(), "big.txt"))
+	var statusErr *filer.HTTPStatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	assert.Equal(t, int32(fastRetry.MaxRetries), resumes.Load())
+}
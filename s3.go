@@ -0,0 +1,453 @@
+package filer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3MinPartSize     = 5 << 20 // S3 要求除最后一块外每块不小于 5MiB
+	s3DefaultPartSize = 8 << 20
+	s3EmptyHash       = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// S3Config S3 兼容存储（AWS S3、MinIO 等）的配置
+type S3Config struct {
+	Endpoint        string       // 服务地址，如 "https://s3.us-east-1.amazonaws.com"、"http://minio:9000"
+	Region          string       // 区域，默认 us-east-1
+	Bucket          string       // 存储桶
+	AccessKeyID     string       // 访问密钥 ID
+	SecretAccessKey string       // 访问密钥
+	SessionToken    string       // 临时凭证的会话令牌，可选
+	PathStyle       bool         // 使用路径风格地址（endpoint/bucket/key），MinIO 通常需要开启
+	PartSize        int64        // 分片上传的分片大小，默认 8MiB，最小 5MiB；内容不足一个分片时使用单次 PUT
+	BaseURL         string       // 对外访问的 URL 前缀，为空时使用对象的 S3 地址
+	URLFunc         URLFunc      // 设置后忽略 BaseURL，由其生成 URL
+	HTTPClient      *http.Client // 为空时使用 http.DefaultClient
+}
+
+// S3Storage 基于 S3 REST 协议与 SigV4 签名的存储后端
+type S3Storage struct {
+	cfg      S3Config
+	endpoint *url.URL
+}
+
+// S3Error S3 服务返回的错误
+type S3Error struct {
+	StatusCode int
+	Code       string // 如 NoSuchKey、AccessDenied
+	Message    string
+}
+
+func (e *S3Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("filer: s3 response status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("filer: s3 %s (%d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+// NewS3Storage 创建 S3 兼容存储
+func NewS3Storage(cfg S3Config) (*S3Storage, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("filer: s3 bucket is empty")
+	}
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("filer: invalid s3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.PartSize <= 0 {
+		cfg.PartSize = s3DefaultPartSize
+	} else if cfg.PartSize < s3MinPartSize {
+		cfg.PartSize = s3MinPartSize
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	return &S3Storage{cfg: cfg, endpoint: u}, nil
+}
+
+// objectURL 返回对象的 S3 地址
+func (s *S3Storage) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	base := strings.TrimSuffix(u.Path, "/")
+	if s.cfg.PathStyle {
+		u.Path = base + "/" + s.cfg.Bucket + "/" + key
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+		u.Path = base + "/" + key
+	}
+	u.RawPath = ""
+	u.RawQuery = ""
+	return &u
+}
+
+func (s *S3Storage) URL(key string) string {
+	key, err := cleanKey(key)
+	if err != nil {
+		return ""
+	}
+	if s.cfg.URLFunc != nil || s.cfg.BaseURL != "" {
+		return buildURL(s.cfg.URLFunc, s.cfg.BaseURL, key)
+	}
+	u := s.objectURL(key)
+	return u.Scheme + "://" + u.Host + s3EncodePath(u.Path)
+}
+
+// s3Encode 按 SigV4 规则进行 URI 编码（仅保留 RFC 3986 非保留字符）
+func s3Encode(s string, encodeSlash bool) string {
+	var sb strings.Builder
+	for _, b := range []byte(s) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~':
+			sb.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			sb.WriteByte(b)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", b)
+		}
+	}
+	return sb.String()
+}
+
+// s3EncodePath 编码对象路径（保留 "/"）
+func s3EncodePath(p string) string {
+	return s3Encode(p, false)
+}
+
+// s3CanonicalQuery 生成排序后的规范查询字符串
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, s3Encode(k, true)+"="+s3Encode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// signV4 使用 AWS Signature Version 4 为请求签名，req.Header 中已有的请求头（及 Host）全部参与签名
+func signV4(req *http.Request, accessKeyID, secretAccessKey, region, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, values := range req.Header {
+		lk := strings.ToLower(k)
+		if lk == "authorization" || lk == "user-agent" {
+			continue
+		}
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		headers[lk] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EncodePath(req.URL.Path),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+// do 构造、签名并发送请求；body 为 nil 表示空请求体。非 2xx 响应会被转换为 *S3Error。
+func (s *S3Storage) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u := s.objectURL(key)
+	u.RawPath = s3EncodePath(u.Path)
+	if query != nil {
+		u.RawQuery = s3CanonicalQuery(query)
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	for k, values := range header {
+		req.Header[k] = values
+	}
+	if s.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.cfg.SessionToken)
+	}
+	payloadHash := s3EmptyHash
+	if body != nil {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+	signV4(req, s.cfg.AccessKeyID, s.cfg.SecretAccessKey, s.cfg.Region, payloadHash, time.Now())
+
+	resp, err := s.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	s3Err := &S3Error{StatusCode: resp.StatusCode}
+	if method != http.MethodHead {
+		var e struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		if b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10)); len(b) != 0 && xml.Unmarshal(b, &e) == nil {
+			s3Err.Code, s3Err.Message = e.Code, e.Message
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound && (s3Err.Code == "" || s3Err.Code == "NoSuchKey") {
+		return nil, fmt.Errorf("%w, %w", ErrObjectNotFound, s3Err)
+	}
+	return nil, s3Err
+}
+
+// putHeader 生成内容类型与 x-amz-meta-* 元数据请求头
+func putHeader(opts PutOptions) http.Header {
+	header := make(http.Header)
+	if opts.ContentType != "" {
+		header.Set("Content-Type", opts.ContentType)
+	}
+	for k, v := range opts.Metadata {
+		header.Set("X-Amz-Meta-"+k, v)
+	}
+	return header
+}
+
+// Put 写入对象。内容小于一个分片时使用单次 PUT，否则按 PartSize 分片上传（任一步失败会中止分片上传）。
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, opts PutOptions) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	r = &contextReader{ctx: ctx, r: r}
+	first, err := readPart(r, s.cfg.PartSize, opts.Size)
+	if err != nil {
+		return err
+	}
+	if int64(len(first)) < s.cfg.PartSize {
+		resp, err := s.do(ctx, http.MethodPut, key, nil, putHeader(opts), first)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+	return s.putMultipart(ctx, key, first, r, opts)
+}
+
+// readPart 读取至多 size 字节，流结束时返回的数据可能不足 size。
+// hint 为预计的字节数（未知时为 -1），小于 size 时按 hint 分配缓冲区，避免小对象也占用整个分片大小的内存。
+func readPart(r io.Reader, size, hint int64) ([]byte, error) {
+	if hint >= 0 && hint < size {
+		var b bytes.Buffer
+		// 多留 MinRead 字节，读到 EOF 时无需扩容
+		b.Grow(int(hint) + bytes.MinRead)
+		_, err := io.CopyN(&b, r, size)
+		if err == io.EOF {
+			err = nil
+		}
+		return b.Bytes(), err
+	}
+	buf := make([]byte, size)
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return buf[:n], err
+}
+
+type s3CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// putMultipart 分片上传：初始化、逐片上传、完成；失败时中止上传以释放服务端分片
+func (s *S3Storage) putMultipart(ctx context.Context, key string, first []byte, r io.Reader, opts PutOptions) (err error) {
+	resp, err := s.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, putHeader(opts), nil)
+	if err != nil {
+		return err
+	}
+	var initiate struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err = xml.NewDecoder(resp.Body).Decode(&initiate)
+	_ = resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	if initiate.UploadID == "" {
+		return errors.New("filer: s3 multipart upload id is empty")
+	}
+	defer func() {
+		if err != nil {
+			// 使用独立的 ctx，保证调用方取消后仍能中止上传
+			abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+			defer cancel()
+			if resp, aerr := s.do(abortCtx, http.MethodDelete, key, url.Values{"uploadId": {initiate.UploadID}}, nil, nil); aerr == nil {
+				_ = resp.Body.Close()
+			}
+		}
+	}()
+
+	var parts []s3CompletedPart
+	part := first
+	for number := 1; len(part) != 0; number++ {
+		query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {initiate.UploadID}}
+		resp, err = s.do(ctx, http.MethodPut, key, query, nil, part)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		parts = append(parts, s3CompletedPart{PartNumber: number, ETag: resp.Header.Get("ETag")})
+		if int64(len(part)) < s.cfg.PartSize {
+			break
+		}
+		if part, err = readPart(r, s.cfg.PartSize, -1); err != nil {
+			return err
+		}
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+		Parts   []s3CompletedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+	resp, err = s.do(ctx, http.MethodPost, key, url.Values{"uploadId": {initiate.UploadID}}, nil, body)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	// CompleteMultipartUpload 可能返回 200 但响应体为错误
+	var result struct {
+		XMLName xml.Name
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if b, _ := io.ReadAll(resp.Body); len(b) != 0 && xml.Unmarshal(b, &result) == nil && result.XMLName.Local == "Error" {
+		return &S3Error{StatusCode: resp.StatusCode, Code: result.Code, Message: result.Message}
+	}
+	return nil
+}
+
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Storage) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil, nil)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	_ = resp.Body.Close()
+	info := ObjectInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = t
+	}
+	for k, values := range resp.Header {
+		if name, ok := strings.CutPrefix(strings.ToLower(k), "x-amz-meta-"); ok && len(values) != 0 {
+			if info.Metadata == nil {
+				info.Metadata = make(map[string]string)
+			}
+			info.Metadata[name] = values[0]
+		}
+	}
+	return info, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (s *S3Storage) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.Stat(ctx, key)
+	if errors.Is(err, ErrObjectNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,220 @@
+package filer_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 路径风格的最小 S3 实现，支持对象读写、HEAD、删除与分片上传
+type fakeS3 struct {
+	t       *testing.T
+	bucket  string
+	mu      sync.Mutex
+	objects map[string][]byte
+	headers map[string]http.Header
+	uploads map[string]map[int][]byte
+	nextID  int
+	aborted int
+}
+
+func newFakeS3(t *testing.T, bucket string) *fakeS3 {
+	return &fakeS3{
+		t:       t,
+		bucket:  bucket,
+		objects: map[string][]byte{},
+		headers: map[string]http.Header{},
+		uploads: map[string]map[int][]byte{},
+	}
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || r.Header.Get("X-Amz-Date") == "" ||
+		r.Header.Get("X-Amz-Content-Sha256") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>bad signature</Message></Error>`)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+s.bucket+"/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprint(w, `<Error><Code>NoSuchBucket</Code></Error>`)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		s.nextID++
+		id := strconv.Itoa(s.nextID)
+		s.uploads[id] = map[int][]byte{}
+		s.headers[key] = r.Header.Clone()
+		_, _ = fmt.Fprintf(w, `<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>`, id)
+	case r.Method == http.MethodPut && q.Has("uploadId"):
+		n, _ := strconv.Atoi(q.Get("partNumber"))
+		s.uploads[q.Get("uploadId")][n] = body
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, n))
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		var complete struct {
+			Parts []struct {
+				PartNumber int
+				ETag       string
+			} `xml:"Part"`
+		}
+		require.NoError(s.t, xml.Unmarshal(body, &complete))
+		parts := s.uploads[q.Get("uploadId")]
+		var numbers []int
+		for n := range parts {
+			numbers = append(numbers, n)
+		}
+		sort.Ints(numbers)
+		var data []byte
+		for i, n := range numbers {
+			assert.Equal(s.t, fmt.Sprintf(`"etag-%d"`, n), complete.Parts[i].ETag)
+			data = append(data, parts[n]...)
+		}
+		s.objects[key] = data
+		delete(s.uploads, q.Get("uploadId"))
+		_, _ = fmt.Fprint(w, `<CompleteMultipartUploadResult></CompleteMultipartUploadResult>`)
+	case r.Method == http.MethodDelete && q.Has("uploadId"):
+		s.aborted++
+		delete(s.uploads, q.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		s.objects[key] = body
+		s.headers[key] = r.Header.Clone()
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := s.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				_, _ = fmt.Fprint(w, `<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`)
+			}
+			return
+		}
+		for k, v := range s.headers[key] {
+			if k == "Content-Type" || strings.HasPrefix(k, "X-Amz-Meta-") {
+				w.Header()[k] = v
+			}
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestS3Storage(t *testing.T) (*filer.S3Storage, *fakeS3) {
+	fake := newFakeS3(t, "uploads")
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	storage, err := filer.NewS3Storage(filer.S3Config{
+		Endpoint:        srv.URL,
+		Bucket:          "uploads",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		PathStyle:       true,
+	})
+	require.NoError(t, err)
+	return storage, fake
+}
+
+func TestS3Storage(t *testing.T) {
+	storage, _ := newTestS3Storage(t)
+	testStorage(t, storage)
+}
+
+func TestS3Storage_MetadataAndErrors(t *testing.T) {
+	storage, _ := newTestS3Storage(t)
+	ctx := context.Background()
+	err := storage.Put(ctx, "docs/a b.txt", strings.NewReader("hello"), filer.PutOptions{
+		Size:        5,
+		ContentType: "text/plain",
+		Metadata:    map[string]string{"owner": "alice"},
+	})
+	require.NoError(t, err)
+	info, err := storage.Stat(ctx, "docs/a b.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), info.Size)
+	assert.Equal(t, "text/plain", info.ContentType)
+	assert.Equal(t, map[string]string{"owner": "alice"}, info.Metadata)
+
+	_, err = storage.Get(ctx, "missing.txt")
+	assert.ErrorIs(t, err, filer.ErrObjectNotFound)
+	var s3Err *filer.S3Error
+	require.ErrorAs(t, err, &s3Err)
+	assert.Equal(t, "NoSuchKey", s3Err.Code)
+}
+
+func TestS3Storage_Multipart(t *testing.T) {
+	storage, fake := newTestS3Storage(t)
+	ctx := context.Background()
+	data := bytes.Repeat([]byte("0123456789abcdef"), (11<<20)/16)
+	require.NoError(t, storage.Put(ctx, "big.bin", bytes.NewReader(data), filer.PutOptions{Size: -1}))
+	assert.Equal(t, data, fake.objects["big.bin"])
+	assert.Empty(t, fake.uploads)
+
+	// 读取失败时中止分片上传
+	r := io.MultiReader(bytes.NewReader(data[:9<<20]), errReader{})
+	assert.Error(t, storage.Put(ctx, "broken.bin", r, filer.PutOptions{Size: -1}))
+	assert.Equal(t, 1, fake.aborted)
+	assert.Empty(t, fake.uploads)
+}
+
+// TestS3Storage_SizeHint 验证 Size 只用于分配缓冲区，与实际长度不符时仍写入完整内容。
+func TestS3Storage_SizeHint(t *testing.T) {
+	storage, fake := newTestS3Storage(t)
+	ctx := context.Background()
+	require.NoError(t, storage.Put(ctx, "short.txt", strings.NewReader("hello world"), filer.PutOptions{Size: 5}))
+	assert.Equal(t, []byte("hello world"), fake.objects["short.txt"])
+	require.NoError(t, storage.Put(ctx, "empty.txt", strings.NewReader(""), filer.PutOptions{Size: 0}))
+	assert.Empty(t, fake.objects["empty.txt"])
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), (11<<20)/16)
+	require.NoError(t, storage.Put(ctx, "big.bin", bytes.NewReader(data), filer.PutOptions{Size: 1024}))
+	assert.Equal(t, data, fake.objects["big.bin"])
+	assert.Empty(t, fake.uploads)
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, io.ErrClosedPipe }
+
+func TestS3Storage_URL(t *testing.T) {
+	virtual, err := filer.NewS3Storage(filer.S3Config{Endpoint: "https://s3.example.com", Bucket: "media"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://media.s3.example.com/a%20b/c.jpg", virtual.URL("a b/c.jpg"))
+
+	pathStyle, err := filer.NewS3Storage(filer.S3Config{Endpoint: "http://minio:9000", Bucket: "media", PathStyle: true})
+	require.NoError(t, err)
+	assert.Equal(t, "http://minio:9000/media/c.jpg", pathStyle.URL("c.jpg"))
+
+	cdn, err := filer.NewS3Storage(filer.S3Config{Endpoint: "http://minio:9000", Bucket: "media", BaseURL: "https://cdn.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/c.jpg", cdn.URL("c.jpg"))
+
+	_, err = filer.NewS3Storage(filer.S3Config{Endpoint: "minio:9000", Bucket: "media"})
+	assert.Error(t, err)
+}
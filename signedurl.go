@@ -0,0 +1,147 @@
+package filer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// 签名 URL 使用的查询参数
+const (
+	signParamExpires     = "expires"
+	signParamIP          = "ip"
+	signParamDisposition = "disposition"
+	signParamSignature   = "signature"
+)
+
+// SignOptions 签名 URL 的选项
+type SignOptions struct {
+	Expires            time.Duration // 有效期，必须大于 0
+	IP                 string        // 非空时仅允许该客户端 IP 访问
+	ContentDisposition string        // 非空时 Middleware 以此作为响应的 Content-Disposition，如 `attachment; filename="invoice.pdf"`
+}
+
+// URLSigner 生成与校验带过期时间的 HMAC-SHA256 签名 URL。
+// 签名覆盖路径与全部查询参数（不含主机名，便于经由 CDN、反向代理访问），任何改动都会使签名失效。
+type URLSigner struct {
+	Key      []byte                       // HMAC 密钥
+	ClientIP func(r *http.Request) string // 取客户端 IP，为空时使用 RemoteAddr；位于反向代理之后时需自行从可信头部读取
+	Now      func() time.Time             // 当前时间，为空时使用 time.Now，便于测试
+}
+
+// NewURLSigner 创建 URLSigner
+func NewURLSigner(key []byte) *URLSigner {
+	return &URLSigner{Key: key}
+}
+
+func (s *URLSigner) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// Sign 为 rawURL（绝对 URL 或以 "/" 开头的路径，如 Uri() 的结果）追加过期时间、绑定信息与签名参数
+func (s *URLSigner) Sign(rawURL string, opts SignOptions) (string, error) {
+	if len(s.Key) == 0 {
+		return "", errors.New("filer: url signer key is empty")
+	}
+	if opts.Expires <= 0 {
+		return "", errors.New("filer: signed url expires must be positive")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	for _, name := range []string{signParamExpires, signParamIP, signParamDisposition, signParamSignature} {
+		query.Del(name)
+	}
+	query.Set(signParamExpires, strconv.FormatInt(s.now().Add(opts.Expires).Unix(), 10))
+	if opts.IP != "" {
+		query.Set(signParamIP, opts.IP)
+	}
+	if opts.ContentDisposition != "" {
+		query.Set(signParamDisposition, opts.ContentDisposition)
+	}
+	query.Set(signParamSignature, s.signature(u.EscapedPath(), query))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// signature 计算 path 与 query（不含 signature 参数，按键排序编码）的 HMAC-SHA256，结果为 URL 安全的 base64
+func (s *URLSigner) signature(escapedPath string, query url.Values) string {
+	values := make(url.Values, len(query))
+	for k, v := range query {
+		if k != signParamSignature {
+			values[k] = v
+		}
+	}
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write([]byte(escapedPath + "?" + values.Encode()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 校验请求的签名、过期时间与绑定的客户端 IP；失败时返回的错误满足 errors.Is(err, ErrSignatureInvalid) 或 ErrSignatureExpired
+func (s *URLSigner) Verify(r *http.Request) error {
+	if len(s.Key) == 0 {
+		return fmt.Errorf("%w, key is empty", ErrSignatureInvalid)
+	}
+	query := r.URL.Query()
+	sig := query.Get(signParamSignature)
+	if sig == "" {
+		return fmt.Errorf("%w, missing signature", ErrSignatureInvalid)
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.signature(r.URL.EscapedPath(), query))) {
+		return ErrSignatureInvalid
+	}
+	expires, err := strconv.ParseInt(query.Get(signParamExpires), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w, invalid expires", ErrSignatureInvalid)
+	}
+	if s.now().Unix() > expires {
+		return ErrSignatureExpired
+	}
+	if ip := query.Get(signParamIP); ip != "" && ip != s.clientIP(r) {
+		return fmt.Errorf("%w, client ip mismatch", ErrSignatureInvalid)
+	}
+	return nil
+}
+
+func (s *URLSigner) clientIP(r *http.Request) string {
+	if s.ClientIP != nil {
+		return s.ClientIP(r)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Middleware 校验签名后再交给 next 处理，失败时返回 403；签名绑定了 Content-Disposition 时写入响应头。
+// 签名覆盖完整路径，需放在 http.StripPrefix 等改写路径的处理器之外。
+func (s *URLSigner) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := s.Verify(r); err != nil {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		if disposition := r.URL.Query().Get(signParamDisposition); disposition != "" {
+			w.Header().Set("Content-Disposition", disposition)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// FileServer 返回校验签名后从 dir（通常为 SaveTo 的保存目录）提供文件的处理器，prefix 为对外 URL 的路径前缀（如 "/files/"）
+func (s *URLSigner) FileServer(prefix, dir string) http.Handler {
+	return s.Middleware(http.StripPrefix(prefix, http.FileServer(http.Dir(dir))))
+}
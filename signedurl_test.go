@@ -0,0 +1,105 @@
+package filer_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestURLSigner_Verify 验证签名、过期、篡改与 IP 绑定的校验结果。
+func TestURLSigner_Verify(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	signer := filer.NewURLSigner([]byte("secret"))
+	signer.Now = func() time.Time { return now }
+
+	signed, err := signer.Sign("https://cdn.example.com/invoices/2026/a b.pdf?v=2", filer.SignOptions{
+		Expires: time.Hour,
+		IP:      "203.0.113.7",
+	})
+	require.NoError(t, err)
+
+	request := func(rawURL, remoteAddr string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, rawURL, nil)
+		r.RemoteAddr = remoteAddr
+		return r
+	}
+	assert.NoError(t, signer.Verify(request(signed, "203.0.113.7:5555")))
+	assert.ErrorIs(t, signer.Verify(request(signed, "198.51.100.1:5555")), filer.ErrSignatureInvalid)
+
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	tampered := *u
+	tampered.Path = "/invoices/2026/other.pdf"
+	assert.ErrorIs(t, signer.Verify(request(tampered.String(), "203.0.113.7:1")), filer.ErrSignatureInvalid)
+	q := u.Query()
+	q.Set("v", "3")
+	tampered = *u
+	tampered.RawQuery = q.Encode()
+	assert.ErrorIs(t, signer.Verify(request(tampered.String(), "203.0.113.7:1")), filer.ErrSignatureInvalid)
+	q = u.Query()
+	q.Set("expires", "9999999999")
+	tampered.RawQuery = q.Encode()
+	assert.ErrorIs(t, signer.Verify(request(tampered.String(), "203.0.113.7:1")), filer.ErrSignatureInvalid)
+
+	other := filer.NewURLSigner([]byte("other"))
+	assert.ErrorIs(t, other.Verify(request(signed, "203.0.113.7:1")), filer.ErrSignatureInvalid)
+
+	now = now.Add(2 * time.Hour)
+	assert.ErrorIs(t, signer.Verify(request(signed, "203.0.113.7:1")), filer.ErrSignatureExpired)
+
+	_, err = signer.Sign("/a.pdf", filer.SignOptions{})
+	assert.Error(t, err)
+}
+
+// TestURLSigner_FileServer 验证中间件仅在签名有效时提供保存目录中的文件，并写入绑定的 Content-Disposition。
+func TestURLSigner_FileServer(t *testing.T) {
+	dir := t.TempDir()
+	f := filer.NewFiler(filer.WithURLBuilder(filer.NewURLBuilder(dir, "/files")))
+	require.NoError(t, f.Open([]byte("invoice body")))
+	_, err := f.SaveTo(filepath.Join(dir, "invoice 42.pdf"))
+	require.NoError(t, err)
+	require.Equal(t, "/files/invoice%2042.pdf", f.Uri())
+
+	signer := filer.NewURLSigner([]byte("secret"))
+	srv := httptest.NewServer(signer.FileServer("/files/", dir))
+	defer srv.Close()
+
+	signed, err := signer.Sign(f.Uri(), filer.SignOptions{
+		Expires:            time.Minute,
+		ContentDisposition: `attachment; filename="invoice.pdf"`,
+	})
+	require.NoError(t, err)
+
+	resp, err := http.Get(srv.URL + signed)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "invoice body", string(body))
+	assert.Equal(t, `attachment; filename="invoice.pdf"`, resp.Header.Get("Content-Disposition"))
+
+	for _, u := range []string{srv.URL + f.Uri(), srv.URL + "/files/other.pdf?" + mustQuery(t, signed)} {
+		resp, err = http.Get(u)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode, u)
+	}
+	_, err = os.Stat(filepath.Join(dir, "invoice 42.pdf"))
+	assert.NoError(t, err)
+}
+
+func mustQuery(t *testing.T, rawURL string) string {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	return u.RawQuery
+}
@@ -0,0 +1,311 @@
+package filer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// sniffLen 内容识别读取的字节数，tar 的 ustar 标记位于 257 字节处，ftyp 兼容品牌列表也可能较长
+const sniffLen = 4096
+
+// signature 固定偏移处的魔数
+type signature struct {
+	offset   int
+	magic    string
+	mimeType string
+	ext      string
+}
+
+// signatures http.DetectContentType 不识别或识别不够具体的格式，按顺序匹配
+var signatures = []signature{
+	{0, "7z\xbc\xaf\x27\x1c", "application/x-7z-compressed", ".7z"},
+	{0, "Rar!\x1a\x07", "application/vnd.rar", ".rar"},
+	{0, "\x1f\x8b", "application/gzip", ".gz"},
+	{0, "BZh", "application/x-bzip2", ".bz2"},
+	{0, "\xfd7zXZ\x00", "application/x-xz", ".xz"},
+	{0, "\x28\xb5\x2f\xfd", "application/zstd", ".zst"},
+	{257, "ustar", "application/x-tar", ".tar"},
+	{0, "8BPS", "image/vnd.adobe.photoshop", ".psd"},
+	{0, "\x00\x00\x01\x00", "image/x-icon", ".ico"},
+	{0, "II*\x00", "image/tiff", ".tiff"},
+	{0, "MM\x00*", "image/tiff", ".tiff"},
+	{0, "\x00\x00\x00\x0cjP  \r\n\x87\n", "image/jp2", ".jp2"},
+	{0, "\x00\x00\x00\x0cJXL \r\n\x87\n", "image/jxl", ".jxl"},
+	{0, "\xff\x0a", "image/jxl", ".jxl"},
+	{0, "\x00asm", "application/wasm", ".wasm"},
+	{0, "SQLite format 3\x00", "application/vnd.sqlite3", ".sqlite"},
+	{0, "\x7fELF", "application/x-elf", ".elf"},
+	{0, "dex\n", "application/vnd.android.dex", ".dex"},
+	{0, "\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1", "application/x-ole-storage", ""},
+	{0, "{\\rtf", "application/rtf", ".rtf"},
+	{0, "%!PS", "application/postscript", ".ps"},
+	{0, "fLaC", "audio/flac", ".flac"},
+	{0, "#!AMR", "audio/amr", ".amr"},
+	{0, "FLV\x01", "video/x-flv", ".flv"},
+	{0, "\x00\x01\x00\x00\x00", "font/ttf", ".ttf"},
+	{0, "OTTO", "font/otf", ".otf"},
+	{0, "ttcf", "font/collection", ".ttc"},
+	{0, "BEGIN:VCARD", "text/vcard", ".vcf"},
+	{0, "BEGIN:VCALENDAR", "text/calendar", ".ics"},
+}
+
+// compatibleExts 同一识别结果可对应多个扩展名（容器格式、别名），声明的扩展名在其中时沿用声明的扩展名
+var compatibleExts = map[string][]string{
+	"application/zip":                               {".zip", ".docx", ".xlsx", ".pptx", ".odt", ".ods", ".odp", ".epub", ".jar", ".war", ".apk", ".xpi", ".ipa", ".nupkg", ".whl"},
+	"application/x-ole-storage":                     {".doc", ".xls", ".ppt", ".msi", ".msg", ".vsd"},
+	"application/vnd.microsoft.portable-executable": {".exe", ".dll", ".sys", ".scr", ".ocx", ".cpl", ".efi"},
+	"application/x-elf":                             {".elf", ".so", ".o", ".bin"},
+	"application/gzip":                              {".gz", ".tgz"},
+	"application/x-bzip2":                           {".bz2", ".tbz2"},
+	"application/x-xz":                              {".xz", ".txz"},
+	"image/tiff":                                    {".tiff", ".tif", ".dng", ".cr2", ".nef", ".arw"},
+	"image/jpeg":                                    {".jpg", ".jpeg", ".jpe", ".jfif"},
+	"image/heic":                                    {".heic", ".heif"},
+	"image/heif":                                    {".heif", ".heic"},
+	"video/mp4":                                     {".mp4", ".m4v", ".m4a", ".f4v"},
+	"application/vnd.sqlite3":                       {".sqlite", ".sqlite3", ".db"},
+	"text/xml":                                      {".xml", ".svg", ".xhtml", ".xsd", ".xsl", ".rss", ".atom", ".kml", ".gpx", ".plist"},
+	"text/html":                                     {".html", ".htm", ".xhtml", ".shtml"},
+}
+
+// sniffResult 内容识别结果
+type sniffResult struct {
+	mimeType string // 识别出的类型，文本类型可能带 charset 参数
+	ext      string // 签名库给出的扩展名，为空时由 mimeType 推断
+}
+
+// sniffContent 按内容识别类型：先查签名库与容器格式（ZIP、ISO-BMFF、EBML），再回退到 http.DetectContentType。
+// head 为内容开头（建议至少 sniffLen 字节）；ra 不为空时用于读取 ZIP 中央目录，size 为内容总长度。
+func sniffContent(head []byte, ra io.ReaderAt, size int64) sniffResult {
+	if bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+		return sniffZip(head, ra, size)
+	}
+	if len(head) >= 12 && string(head[4:8]) == "ftyp" {
+		return sniffFtyp(head)
+	}
+	if bytes.HasPrefix(head, []byte("\x1a\x45\xdf\xa3")) {
+		return sniffEBML(head)
+	}
+	if isPE(head) {
+		return sniffResult{mimeType: "application/vnd.microsoft.portable-executable", ext: ".exe"}
+	}
+	for _, sig := range signatures {
+		if len(head) >= sig.offset+len(sig.magic) && string(head[sig.offset:sig.offset+len(sig.magic)]) == sig.magic {
+			return sniffResult{mimeType: sig.mimeType, ext: sig.ext}
+		}
+	}
+	return sniffResult{mimeType: http.DetectContentType(head)}
+}
+
+// isPE 判断是否为 PE 可执行文件：仅凭 "MZ" 会把以 MZ 开头的文本误判为 .exe，还需 0x3C 处的 e_lfanew 指向 "PE\0\0"
+func isPE(head []byte) bool {
+	if len(head) < 0x40 || !bytes.HasPrefix(head, []byte("MZ")) {
+		return false
+	}
+	offset := int64(binary.LittleEndian.Uint32(head[0x3c:0x40]))
+	return offset >= 0x40 && offset+4 <= int64(len(head)) && string(head[offset:offset+4]) == "PE\x00\x00"
+}
+
+// zipTypes 按 ZIP 中的特征文件识别具体格式
+var zipTypes = []struct {
+	entry    string // 文件名或目录前缀（以 "/" 结尾）
+	mimeType string
+	ext      string
+}{
+	{"word/", "application/vnd.openxmlformats-officedocument.wordprocessingml.document", ".docx"},
+	{"xl/", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", ".xlsx"},
+	{"ppt/", "application/vnd.openxmlformats-officedocument.presentationml.presentation", ".pptx"},
+	{"AndroidManifest.xml", "application/vnd.android.package-archive", ".apk"},
+	{"META-INF/MANIFEST.MF", "application/java-archive", ".jar"},
+}
+
+// zipMimetypes ODF、EPUB 等在 "mimetype" 文件中声明的类型
+var zipMimetypes = map[string]string{
+	"application/epub+zip":                            ".epub",
+	"application/vnd.oasis.opendocument.text":         ".odt",
+	"application/vnd.oasis.opendocument.spreadsheet":  ".ods",
+	"application/vnd.oasis.opendocument.presentation": ".odp",
+	"application/vnd.oasis.opendocument.graphics":     ".odg",
+}
+
+// sniffZip 识别 ZIP 容器：有 ra 时读取中央目录，否则扫描 head 中的本地文件头
+func sniffZip(head []byte, ra io.ReaderAt, size int64) sniffResult {
+	var names []string
+	mimetype := ""
+	if ra != nil && size > 0 {
+		if zr, err := zip.NewReader(ra, size); err == nil {
+			for _, file := range zr.File {
+				names = append(names, file.Name)
+				if file.Name == "mimetype" && file.UncompressedSize64 < 128 {
+					if rc, err := file.Open(); err == nil {
+						b, _ := io.ReadAll(io.LimitReader(rc, 128))
+						_ = rc.Close()
+						mimetype = string(b)
+					}
+				}
+			}
+		}
+	}
+	if names == nil {
+		names, mimetype = zipLocalEntries(head)
+	}
+
+	if ext, ok := zipMimetypes[strings.TrimSpace(mimetype)]; ok {
+		return sniffResult{mimeType: strings.TrimSpace(mimetype), ext: ext}
+	}
+	ooxml := slices.Contains(names, "[Content_Types].xml")
+	for _, t := range zipTypes {
+		if strings.HasSuffix(t.entry, "/") && !ooxml {
+			continue
+		}
+		if slices.ContainsFunc(names, func(name string) bool {
+			return name == t.entry || (strings.HasSuffix(t.entry, "/") && strings.HasPrefix(name, t.entry))
+		}) {
+			return sniffResult{mimeType: t.mimeType, ext: t.ext}
+		}
+	}
+	return sniffResult{mimeType: "application/zip", ext: ".zip"}
+}
+
+// zipLocalEntries 扫描 head 中连续的本地文件头，返回文件名与未压缩的 "mimetype" 文件内容
+func zipLocalEntries(head []byte) (names []string, mimetype string) {
+	for p := 0; p+30 <= len(head) && string(head[p:p+4]) == "PK\x03\x04"; {
+		method := binary.LittleEndian.Uint16(head[p+8:])
+		flags := binary.LittleEndian.Uint16(head[p+6:])
+		compressed := int(binary.LittleEndian.Uint32(head[p+18:]))
+		nameLen := int(binary.LittleEndian.Uint16(head[p+26:]))
+		extraLen := int(binary.LittleEndian.Uint16(head[p+28:]))
+		if p+30+nameLen > len(head) {
+			break
+		}
+		name := string(head[p+30 : p+30+nameLen])
+		names = append(names, name)
+		data := p + 30 + nameLen + extraLen
+		if name == "mimetype" && method == zip.Store && data+compressed <= len(head) {
+			mimetype = string(head[data : data+compressed])
+		}
+		// 使用数据描述符时大小未知，无法继续定位下一个文件头
+		if flags&0x8 != 0 {
+			break
+		}
+		p = data + compressed
+	}
+	return names, mimetype
+}
+
+// ftypBrands ISO-BMFF（MP4 家族）按品牌识别，按顺序匹配主品牌与兼容品牌
+var ftypBrands = []struct {
+	brands   []string
+	mimeType string
+	ext      string
+}{
+	{[]string{"avif", "avis"}, "image/avif", ".avif"},
+	{[]string{"heic", "heix", "heim", "heis", "hevc", "hevx"}, "image/heic", ".heic"},
+	{[]string{"mif1", "msf1"}, "image/heif", ".heif"},
+	{[]string{"crx "}, "image/x-canon-cr3", ".cr3"},
+	{[]string{"jp2 "}, "image/jp2", ".jp2"},
+	{[]string{"qt  "}, "video/quicktime", ".mov"},
+	{[]string{"M4A ", "M4B ", "M4P "}, "audio/mp4", ".m4a"},
+	{[]string{"M4V ", "M4VH", "M4VP"}, "video/x-m4v", ".m4v"},
+	{[]string{"3g2a", "3g2b", "3g2c"}, "video/3gpp2", ".3g2"},
+	{[]string{"3gp4", "3gp5", "3gp6", "3gp7", "3ge6", "3ge7", "3gg6", "3gs7"}, "video/3gpp", ".3gp"},
+}
+
+// sniffFtyp 识别 ISO-BMFF：先看主品牌，再看兼容品牌，均不认识时视为 MP4
+func sniffFtyp(head []byte) sniffResult {
+	boxSize := int(binary.BigEndian.Uint32(head[:4]))
+	if boxSize > len(head) || boxSize < 16 {
+		boxSize = min(len(head), 16)
+	}
+	major := string(head[8:12])
+	var compatible []string
+	for p := 16; p+4 <= boxSize; p += 4 {
+		compatible = append(compatible, string(head[p:p+4]))
+	}
+	for _, candidates := range [][]string{{major}, compatible} {
+		for _, b := range ftypBrands {
+			if slices.ContainsFunc(candidates, func(brand string) bool { return slices.Contains(b.brands, brand) }) {
+				return sniffResult{mimeType: b.mimeType, ext: b.ext}
+			}
+		}
+	}
+	return sniffResult{mimeType: "video/mp4", ext: ".mp4"}
+}
+
+// sniffEBML 区分 WebM 与 Matroska
+func sniffEBML(head []byte) sniffResult {
+	header := head[:min(len(head), 64)]
+	if bytes.Contains(header, []byte("webm")) {
+		return sniffResult{mimeType: "video/webm", ext: ".webm"}
+	}
+	return sniffResult{mimeType: "video/x-matroska", ext: ".mkv"}
+}
+
+// compatibleExt 判断声明的扩展名 ext 是否与识别结果一致
+func (r sniffResult) compatibleExt(ext string) bool {
+	return ext != "" && (ext == r.ext || slices.Contains(compatibleExts[mimeBaseType(r.mimeType)], ext))
+}
+
+// seekReaderAt 以 Seek + Read 实现 io.ReaderAt，读取后恢复原位置；不可并发使用
+type seekReaderAt struct {
+	rs io.ReadSeeker
+}
+
+func (r *seekReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	pos, err := r.rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _, _ = r.rs.Seek(pos, io.SeekStart) }()
+	if _, err = r.rs.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.ReadFull(r.rs, p)
+}
+
+// sniff 识别可 Seek 数据源的内容类型，读取位置保持不变；内容为空时返回零值，不可 Seek 时返回 ErrNotSeekable
+func (f *Filer) sniff() (sniffResult, error) {
+	if f.readCloser == nil {
+		return sniffResult{}, ErrNotOpened
+	}
+	rs, ok := f.readCloser.(io.ReadSeeker)
+	if !ok {
+		return sniffResult{}, ErrNotSeekable
+	}
+	head, err := f.peek(sniffLen)
+	if err != nil || len(head) == 0 {
+		// 内容为空时无从识别，由调用方回退到文件名上的扩展名
+		return sniffResult{}, err
+	}
+	var ra io.ReaderAt
+	size := int64(-1)
+	if bytes.HasPrefix(head, []byte("PK\x03\x04")) {
+		if size, err = f.sizeOf(); err == nil && size > 0 {
+			if r, ok := f.readCloser.(io.ReaderAt); ok {
+				ra = r
+			} else {
+				ra = &seekReaderAt{rs: rs}
+			}
+		}
+	}
+	return sniffContent(head, ra, size), nil
+}
+
+// MIMEType 按内容识别类型，如 "image/avif"、"application/vnd.openxmlformats-officedocument.wordprocessingml.document"，
+// 文本类型带 charset 参数（如 "text/plain; charset=utf-8"），无法识别时为 "application/octet-stream"。
+// 不可 Seek 的流会先缓冲为内存流；未打开或内容为空时返回空字符串。
+func (f *Filer) MIMEType() string {
+	if f.readCloser == nil || f.ensureSeekable() != nil {
+		return ""
+	}
+	r, err := f.sniff()
+	if err != nil {
+		return ""
+	}
+	return r.mimeType
+}
@@ -0,0 +1,161 @@
+package filer_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// zipFixture 构造包含 files 的 ZIP；raw 为 true 时不使用数据描述符（与 Office 等生成的文件一致）
+func zipFixture(t *testing.T, raw bool, files ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range files {
+		content := []byte("x")
+		if name == "mimetype" {
+			content = []byte("application/epub+zip")
+		}
+		var fw io.Writer
+		var err error
+		if raw {
+			fw, err = w.CreateRaw(&zip.FileHeader{
+				Name:               name,
+				Method:             zip.Store,
+				CRC32:              crc32.ChecksumIEEE(content),
+				CompressedSize64:   uint64(len(content)),
+				UncompressedSize64: uint64(len(content)),
+			})
+		} else {
+			fw, err = w.Create(name)
+		}
+		require.NoError(t, err)
+		_, err = fw.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// ftypFixture 构造 ISO-BMFF 的 ftyp box
+func ftypFixture(major string, compatible ...string) []byte {
+	box := make([]byte, 16, 16+4*len(compatible)+16)
+	binary.BigEndian.PutUint32(box, uint32(16+4*len(compatible)))
+	copy(box[4:], "ftyp"+major)
+	for _, brand := range compatible {
+		box = append(box, brand...)
+	}
+	return append(box, "\x00\x00\x00\x08free"...)
+}
+
+// peFixture 最小 PE 头：DOS 头的 e_lfanew 指向 "PE\0\0"
+func peFixture() []byte {
+	data := make([]byte, 0x100)
+	copy(data, "MZ\x90\x00\x03")
+	binary.LittleEndian.PutUint32(data[0x3c:], 0x80)
+	copy(data[0x80:], "PE\x00\x00\x4c\x01")
+	return data
+}
+
+func tarFixture(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	require.NoError(t, w.WriteHeader(&tar.Header{Name: "a.txt", Mode: 0644, Size: 1, Format: tar.FormatUSTAR}))
+	_, err := w.Write([]byte("a"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// TestMIMEType 验证签名库与容器格式的识别结果及对应扩展名。
+func TestMIMEType(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []byte
+		mimeType string
+		ext      string
+	}{
+		{"docx", zipFixture(t, false, "[Content_Types].xml", "_rels/.rels", "word/document.xml"), "application/vnd.openxmlformats-officedocument.wordprocessingml.document", ".docx"},
+		{"xlsx", zipFixture(t, false, "[Content_Types].xml", "xl/workbook.xml"), "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", ".xlsx"},
+		{"pptx", zipFixture(t, false, "[Content_Types].xml", "ppt/presentation.xml"), "application/vnd.openxmlformats-officedocument.presentationml.presentation", ".pptx"},
+		{"epub", zipFixture(t, false, "mimetype", "META-INF/container.xml"), "application/epub+zip", ".epub"},
+		{"jar", zipFixture(t, false, "META-INF/MANIFEST.MF", "a/B.class"), "application/java-archive", ".jar"},
+		{"zip", zipFixture(t, false, "a.txt"), "application/zip", ".zip"},
+		{"heic", ftypFixture("heic", "mif1", "heic"), "image/heic", ".heic"},
+		{"heif", ftypFixture("mif1", "mif1", "heic"), "image/heif", ".heif"},
+		{"avif", ftypFixture("avif", "avif", "mif1", "miaf"), "image/avif", ".avif"},
+		{"mp4", ftypFixture("isom", "isom", "iso2", "avc1", "mp41"), "video/mp4", ".mp4"},
+		{"mov", ftypFixture("qt  ", "qt  "), "video/quicktime", ".mov"},
+		{"m4a", ftypFixture("M4A ", "M4A ", "mp42", "isom"), "audio/mp4", ".m4a"},
+		{"3gp", ftypFixture("3gp5", "3gp5", "isom"), "video/3gpp", ".3gp"},
+		{"webm", []byte("\x1a\x45\xdf\xa3\x9f\x42\x86\x81\x01\x42\x82\x84webm"), "video/webm", ".webm"},
+		{"mkv", []byte("\x1a\x45\xdf\xa3\xa3\x42\x86\x81\x01\x42\x82\x88matroska"), "video/x-matroska", ".mkv"},
+		{"7z", []byte("7z\xbc\xaf\x27\x1c\x00\x04"), "application/x-7z-compressed", ".7z"},
+		{"rar", []byte("Rar!\x1a\x07\x01\x00"), "application/vnd.rar", ".rar"},
+		{"tar", tarFixture(t), "application/x-tar", ".tar"},
+		{"psd", []byte("8BPS\x00\x01\x00\x00"), "image/vnd.adobe.photoshop", ".psd"},
+		{"ico", []byte("\x00\x00\x01\x00\x01\x00\x10\x10"), "image/x-icon", ".ico"},
+		{"wasm", []byte("\x00asm\x01\x00\x00\x00"), "application/wasm", ".wasm"},
+		{"sqlite", []byte("SQLite format 3\x00\x10\x00"), "application/vnd.sqlite3", ".sqlite"},
+		{"pe", peFixture(), "application/vnd.microsoft.portable-executable", ".exe"},
+		{"mz text", []byte("MZ-2000 service manual\n"), "text/plain; charset=utf-8", ".txt"},
+		{"mz without pe header", append([]byte("MZ\x90\x00\x03"), make([]byte, 100)...), "application/octet-stream", ".bin"},
+		{"png", pngFixture(2, 2), "image/png", ".png"},
+		{"text", []byte("hello"), "text/plain; charset=utf-8", ".txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := filer.NewFiler()
+			defer func() { _ = f.Close() }()
+			require.NoError(t, f.Open(tt.content))
+			assert.Equal(t, tt.mimeType, f.MIMEType())
+			assert.Equal(t, tt.ext, f.Ext())
+		})
+	}
+}
+
+// TestMIMEType_ZipLocalHeaders 验证无法读取中央目录（不可 Seek 的流只看开头）时按本地文件头识别。
+func TestMIMEType_ZipLocalHeaders(t *testing.T) {
+	docx := zipFixture(t, true, "[Content_Types].xml", "_rels/.rels", "word/document.xml")
+	f := filer.NewFiler()
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open(struct{ io.Reader }{bytes.NewReader(docx)}))
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.wordprocessingml.document", f.MIMEType())
+
+	// 不含数据描述符的 EPUB 同样可识别
+	epub := zipFixture(t, true, "mimetype", "OEBPS/content.opf")
+	f2 := filer.NewFiler()
+	defer func() { _ = f2.Close() }()
+	require.NoError(t, f2.Open(epub))
+	assert.Equal(t, ".epub", f2.Ext())
+}
+
+// TestExt_DeclaredCompatible 验证声明的扩展名与识别结果一致时沿用声明的扩展名。
+func TestExt_DeclaredCompatible(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "legacy.xlsm")
+	require.NoError(t, os.WriteFile(path, zipFixture(t, false, "a.txt"), 0644))
+	f := filer.NewFiler()
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open(path))
+	// .xlsm 不在 ZIP 的兼容列表中，以识别结果为准
+	assert.Equal(t, ".zip", f.Ext())
+
+	path = filepath.Join(dir, "photo.HEIF")
+	require.NoError(t, os.WriteFile(path, ftypFixture("heic", "mif1", "heic"), 0644))
+	require.NoError(t, f.Open(path))
+	assert.Equal(t, ".heif", f.Ext())
+	_, mismatch := f.ExtMismatch()
+	assert.False(t, mismatch)
+}
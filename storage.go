@@ -0,0 +1,400 @@
+package filer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ObjectInfo 存储对象的元信息
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+	Metadata    map[string]string
+}
+
+// PutOptions 写入对象时的附加信息
+type PutOptions struct {
+	Size        int64             // 内容长度，未知时为 -1
+	ContentType string            // 内容类型
+	Metadata    map[string]string // 自定义元数据，后端不支持时忽略
+}
+
+// Storage 文件存储后端，key 使用 "/" 分隔，如 "avatars/2024/a.png"
+type Storage interface {
+	// Put 写入对象，已存在时覆盖
+	Put(ctx context.Context, key string, r io.Reader, opts PutOptions) error
+	// Get 读取对象，不存在时返回 ErrObjectNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Stat 获取对象元信息，不存在时返回 ErrObjectNotFound
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	// Delete 删除对象，不存在时不报错
+	Delete(ctx context.Context, key string) error
+	// Exists 判断对象是否存在
+	Exists(ctx context.Context, key string) (bool, error)
+	// URL 返回对象的访问地址
+	URL(key string) string
+}
+
+// cleanKey 规范化存储 key，拒绝空 key 以及跳出根目录的 ".."
+func cleanKey(key string) (string, error) {
+	key = strings.TrimSpace(strings.ReplaceAll(key, `\`, "/"))
+	key = strings.TrimPrefix(path.Clean("/"+key), "/")
+	if key == "" || key == "." {
+		return "", ErrInvalidKey
+	}
+	return key, nil
+}
+
+// escapeKey 逐段转义 key 用于 URL
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// joinURL 拼接 baseURL 与 key，baseURL 为空时返回以 "/" 开头的路径
+func joinURL(baseURL, key string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + escapeKey(key)
+}
+
+// LocalStorage 本地磁盘存储，key 映射为 Root 下的相对路径
+type LocalStorage struct {
+	Root    string  // 根目录
+	BaseURL string  // 对外访问的 URL 前缀，如 "https://cdn.example.com/uploads"，为空时 URL 返回 "/key"
+	URLFunc URLFunc // 设置后忽略 BaseURL，由其生成 URL
+	SyncDir bool    // 写入后是否 fsync 所在目录，见 WithSyncDir
+}
+
+// NewLocalStorage 创建本地磁盘存储
+func NewLocalStorage(root, baseURL string) *LocalStorage {
+	return &LocalStorage{Root: root, BaseURL: baseURL}
+}
+
+// filename 返回 key 对应的本地路径
+func (s *LocalStorage) filename(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Root, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, _ PutOptions) error {
+	filename, err := s.filename(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(filename, func(w io.Writer) error {
+		_, err := io.Copy(w, &contextReader{ctx: ctx, r: r})
+		return err
+	}, s.SyncDir)
+}
+
+func (s *LocalStorage) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	filename, err := s.filename(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return file, err
+}
+
+func (s *LocalStorage) Stat(_ context.Context, key string) (ObjectInfo, error) {
+	filename, err := s.filename(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	info, err := os.Stat(filename)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+		return ObjectInfo{}, ErrObjectNotFound
+	}
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	key, _ = cleanKey(key)
+	return ObjectInfo{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: DefaultMIMERegistry.contentType(path.Ext(key)),
+		ModTime:     info.ModTime(),
+	}, nil
+}
+
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	filename, err := s.filename(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(filename); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalStorage) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.Stat(ctx, key)
+	if errors.Is(err, ErrObjectNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *LocalStorage) URL(key string) string {
+	key, err := cleanKey(key)
+	if err != nil {
+		return ""
+	}
+	return buildURL(s.URLFunc, s.BaseURL, key)
+}
+
+// memoryObject MemoryStorage 中保存的对象
+type memoryObject struct {
+	data []byte
+	info ObjectInfo
+}
+
+// MemoryStorage 内存存储，适合测试；可安全地并发使用
+type MemoryStorage struct {
+	BaseURL string
+	URLFunc URLFunc // 设置后忽略 BaseURL，由其生成 URL
+
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+// NewMemoryStorage 创建内存存储
+func NewMemoryStorage(baseURL string) *MemoryStorage {
+	return &MemoryStorage{BaseURL: baseURL, objects: make(map[string]memoryObject)}
+}
+
+func (s *MemoryStorage) Put(ctx context.Context, key string, r io.Reader, opts PutOptions) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(&contextReader{ctx: ctx, r: r})
+	if err != nil {
+		return err
+	}
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = DefaultMIMERegistry.contentType(path.Ext(key))
+	}
+	var metadata map[string]string
+	if len(opts.Metadata) != 0 {
+		metadata = make(map[string]string, len(opts.Metadata))
+		for k, v := range opts.Metadata {
+			metadata[k] = v
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.objects == nil {
+		s.objects = make(map[string]memoryObject)
+	}
+	s.objects[key] = memoryObject{
+		data: data,
+		info: ObjectInfo{
+			Key:         key,
+			Size:        int64(len(data)),
+			ContentType: contentType,
+			ModTime:     time.Now(),
+			Metadata:    metadata,
+		},
+	}
+	return nil
+}
+
+func (s *MemoryStorage) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	obj, ok := s.objects[key]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return io.NopCloser(bytes.NewReader(obj.data)), nil
+}
+
+func (s *MemoryStorage) Stat(_ context.Context, key string) (ObjectInfo, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	obj, ok := s.objects[key]
+	if !ok {
+		return ObjectInfo{}, ErrObjectNotFound
+	}
+	return obj.info, nil
+}
+
+func (s *MemoryStorage) Delete(_ context.Context, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *MemoryStorage) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.Stat(ctx, key)
+	if errors.Is(err, ErrObjectNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *MemoryStorage) URL(key string) string {
+	key, err := cleanKey(key)
+	if err != nil {
+		return ""
+	}
+	return buildURL(s.URLFunc, s.BaseURL, key)
+}
+
+// Keys 返回全部 key（已排序），便于测试断言
+func (s *MemoryStorage) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]string, 0, len(s.objects))
+	for k := range s.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SaveToStorage 将文件写入 storage 的 key 处，返回最终使用的 key，同时 Uri() 更新为 storage.URL(key)。
+// key 以 "/" 结尾时视为目录，文件名规则与 SaveTo 相同。
+func (f *Filer) SaveToStorage(ctx context.Context, storage Storage, key string) (string, error) {
+	key, err := f.saveToStorage(ctx, storage, key)
+	if err != nil {
+		return "", f.wrapError("save", key, err)
+	}
+	return key, nil
+}
+
+// saveToStorage 执行 SaveToStorage，出错时返回已确定的 key
+func (f *Filer) saveToStorage(ctx context.Context, storage Storage, key string) (string, error) {
+	if f.readCloser == nil {
+		return "", ErrNotOpened
+	}
+	key = strings.TrimSpace(strings.ReplaceAll(key, `\`, "/"))
+	if key == "" {
+		return "", ErrEmptyPath
+	}
+	if strings.HasSuffix(key, "/") {
+		name, err := f.targetName()
+		if err != nil {
+			return "", err
+		}
+		key += name
+	}
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if err = f.validateTarget(path.Base(key)); err != nil {
+		return key, err
+	}
+	if err = f.checkStrictTarget(path.Base(key)); err != nil {
+		return key, err
+	}
+	return key, f.putToStorage(ctx, storage, key)
+}
+
+// putToStorage 从头写入 storage 的 key 处，成功后更新 Uri()
+func (f *Filer) putToStorage(ctx context.Context, storage Storage, key string) error {
+	if err := f.seekStart(); err != nil {
+		return err
+	}
+	size, err := f.sizeOf()
+	if err != nil {
+		size = -1
+	}
+	opts := PutOptions{Size: size, ContentType: f.mimeRegistry().contentType(f.Ext())}
+	r, finish, err := f.hashingReader()
+	if err != nil {
+		return err
+	}
+	// 读到 EOF 时校验，失败则 Put 随之失败，已有对象不会被覆盖
+	v := &verifyingReader{r: r, finish: finish}
+	if err = storage.Put(ctx, key, v, opts); err != nil {
+		return err
+	}
+	if err = v.verify(); err != io.EOF {
+		return err
+	}
+	f.uri = storage.URL(key)
+	return nil
+}
+
+// SaveToStorage 将图像写入 storage 的 key 处（rgba 为空则写出原始字节），key 需包含完整文件名
+func (img *Imager) SaveToStorage(ctx context.Context, storage Storage, key string) error {
+	if err := img.saveToStorage(ctx, storage, key); err != nil {
+		return img.wrapError("save", key, err)
+	}
+	return nil
+}
+
+// saveToStorage 执行 Imager.SaveToStorage
+func (img *Imager) saveToStorage(ctx context.Context, storage Storage, key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	if err = img.validateTarget(path.Base(key)); err != nil {
+		return err
+	}
+	if err = img.checkStrictTarget(path.Base(key)); err != nil {
+		return err
+	}
+	var data []byte
+	if img.rgba == nil {
+		if err = img.loadSourceBytes(); err != nil {
+			return err
+		}
+		data = img.rawBuf
+	} else {
+		var buf bytes.Buffer
+		if err = img.encodeTo(&buf); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	}
+	opts := PutOptions{Size: int64(len(data)), ContentType: img.mimeRegistry().contentType(img.outputFormat())}
+	if err = storage.Put(ctx, key, bytes.NewReader(data), opts); err != nil {
+		return err
+	}
+	img.uri = storage.URL(key)
+	return nil
+}
@@ -0,0 +1,83 @@
+package filer_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStorage 对 Storage 实现执行通用的读写断言
+func testStorage(t *testing.T, storage filer.Storage) {
+	ctx := context.Background()
+	f := filer.NewFiler()
+	defer func() { _ = f.Close() }()
+	require.NoError(t, f.Open("./tests/test.jpg"))
+
+	key, err := f.SaveToStorage(ctx, storage, "images/")
+	require.NoError(t, err)
+	assert.Equal(t, "images/test.jpg", key)
+	assert.Equal(t, storage.URL(key), f.Uri())
+
+	ok, err := storage.Exists(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	info, err := storage.Stat(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, int64(11876), info.Size)
+	assert.Equal(t, "image/jpeg", info.ContentType)
+
+	rc, err := storage.Get(ctx, key)
+	require.NoError(t, err)
+	got, err := io.ReadAll(rc)
+	_ = rc.Close()
+	require.NoError(t, err)
+	want, _ := os.ReadFile("./tests/test.jpg")
+	assert.Equal(t, want, got)
+
+	// ".." 不能跳出根目录
+	key, err = f.SaveToStorage(ctx, storage, "../../escape.jpg")
+	require.NoError(t, err)
+	assert.Equal(t, "escape.jpg", key)
+
+	require.NoError(t, storage.Delete(ctx, "images/test.jpg"))
+	ok, err = storage.Exists(ctx, "images/test.jpg")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	_, err = storage.Get(ctx, "images/test.jpg")
+	assert.ErrorIs(t, err, filer.ErrObjectNotFound)
+	assert.NoError(t, storage.Delete(ctx, "images/test.jpg"))
+}
+
+func TestLocalStorage(t *testing.T) {
+	root := t.TempDir()
+	storage := filer.NewLocalStorage(root, "https://cdn.example.com/uploads/")
+	testStorage(t, storage)
+	assert.FileExists(t, filepath.Join(root, "escape.jpg"))
+	assert.Equal(t, "https://cdn.example.com/uploads/a%20b/c.jpg", storage.URL("a b/c.jpg"))
+}
+
+func TestMemoryStorage(t *testing.T) {
+	storage := filer.NewMemoryStorage("")
+	testStorage(t, storage)
+	assert.Equal(t, []string{"escape.jpg"}, storage.Keys())
+	assert.Equal(t, "/escape.jpg", storage.URL("escape.jpg"))
+}
+
+func TestImager_SaveToStorage(t *testing.T) {
+	img := openImagerFromPNGFile(t, 20, 20)
+	require.NoError(t, img.Resize(8, 8))
+
+	storage := filer.NewMemoryStorage("https://cdn.example.com")
+	require.NoError(t, img.SaveToStorage(context.Background(), storage, "thumbs/small.png"))
+	info, err := storage.Stat(context.Background(), "thumbs/small.png")
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", info.ContentType)
+	assert.Equal(t, "https://cdn.example.com/thumbs/small.png", img.Uri())
+}
@@ -0,0 +1,86 @@
+package filer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// URLFunc 根据 key（以 "/" 分隔，不以 "/" 开头，未转义）生成对外访问的 URL，可用于接入 CDN 改写、签名等
+type URLFunc func(key string) string
+
+// URLBuilder 将存储根目录下的本地文件映射为对外访问的 URL，如 Root 为 "/data/uploads"、
+// BaseURL 为 "https://cdn.example.com/uploads" 时，"/data/uploads/a b.jpg" 对应 "https://cdn.example.com/uploads/a%20b.jpg"。
+type URLBuilder struct {
+	Root    string  // 存储根目录，相对路径基于当前工作目录，为空时为当前工作目录
+	BaseURL string  // 对外访问的 URL 前缀，为空时生成 "/key"
+	URLFunc URLFunc // 设置后忽略 BaseURL，由其生成 URL
+}
+
+// NewURLBuilder 创建 URLBuilder
+func NewURLBuilder(root, baseURL string) *URLBuilder {
+	return &URLBuilder{Root: root, BaseURL: baseURL}
+}
+
+// WithURLBuilder SaveTo、SaveToContentAddressed 成功后使用 b 生成 Uri()；目标不在 b.Root 内时保存前即返回 ErrOutsideRoot。
+// 未设置时沿用原规则：相对路径去掉前导 "." 并以 "/" 开头，绝对路径为空字符串。
+func WithURLBuilder(b *URLBuilder) Option {
+	return func(o *options) {
+		o.urlBuilder = b
+	}
+}
+
+// Key 返回 filename（绝对或相对路径）相对 Root 的 key，filename 不在 Root 内时返回 ErrOutsideRoot
+func (b *URLBuilder) Key(filename string) (string, error) {
+	root := b.Root
+	if root == "" {
+		root = "."
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrOutsideRoot, filename)
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("%w: %s", ErrOutsideRoot, filename)
+	}
+	return rel, nil
+}
+
+// URL 返回 filename 对外访问的 URL，路径逐段转义
+func (b *URLBuilder) URL(filename string) (string, error) {
+	key, err := b.Key(filename)
+	if err != nil {
+		return "", err
+	}
+	return b.KeyURL(key), nil
+}
+
+// KeyURL 返回 key 对外访问的 URL
+func (b *URLBuilder) KeyURL(key string) string {
+	return buildURL(b.URLFunc, b.BaseURL, key)
+}
+
+// buildURL 优先使用 fn，否则拼接 baseURL 与逐段转义后的 key
+func buildURL(fn URLFunc, baseURL, key string) string {
+	if fn != nil {
+		return fn(key)
+	}
+	return joinURL(baseURL, key)
+}
+
+// fileURI SaveTo 等保存到本地后的 Uri()
+func (f *Filer) fileURI(filename string) (string, error) {
+	if f.opts.urlBuilder == nil {
+		return pathURI(filename), nil
+	}
+	return f.opts.urlBuilder.URL(filename)
+}
@@ -0,0 +1,80 @@
+package filer_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestURLBuilder 验证绝对与相对路径都能生成转义后的 URL，根目录外的路径返回 ErrOutsideRoot。
+func TestURLBuilder(t *testing.T) {
+	root := t.TempDir()
+	b := filer.NewURLBuilder(root, "https://cdn.example.com/uploads/")
+
+	u, err := b.URL(filepath.Join(root, "2026", "a b#1?.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/uploads/2026/a%20b%231%3F.jpg", u)
+
+	u, err = b.URL(filepath.Join(root, "x", "..", "图片.png"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/uploads/%E5%9B%BE%E7%89%87.png", u)
+
+	for _, p := range []string{root, filepath.Join(root, "..", "other.jpg"), filepath.Dir(root)} {
+		_, err = b.URL(p)
+		assert.ErrorIs(t, err, filer.ErrOutsideRoot, p)
+	}
+
+	// 相对路径基于当前工作目录
+	rel := filer.NewURLBuilder("./uploads", "")
+	u, err = rel.URL("uploads/avatars/me.png")
+	require.NoError(t, err)
+	assert.Equal(t, "/avatars/me.png", u)
+
+	custom := &filer.URLBuilder{Root: root, URLFunc: func(key string) string { return "cdn://" + key }}
+	u, err = custom.URL(filepath.Join(root, "a.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "cdn://a.jpg", u)
+}
+
+// TestSaveTo_URLBuilder 验证 SaveTo 使用 URLBuilder 生成 Uri()，且根目录外的目标不会被写入。
+func TestSaveTo_URLBuilder(t *testing.T) {
+	root := t.TempDir()
+	f := filer.NewFiler(
+		filer.WithURLBuilder(filer.NewURLBuilder(root, "https://cdn.example.com/uploads")),
+		filer.WithConflictPolicy(filer.ConflictRename),
+	)
+	require.NoError(t, f.Open([]byte("data")))
+	_, err := f.SaveTo(filepath.Join(root, "a b.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/uploads/a%20b.txt", f.Uri())
+
+	// 改名后 Uri() 指向最终文件
+	_, err = f.SaveTo(filepath.Join(root, "a b.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/uploads/a%20b%20%281%29.txt", f.Uri())
+
+	outside := filepath.Join(t.TempDir(), "x.txt")
+	_, err = f.SaveTo(outside)
+	assert.ErrorIs(t, err, filer.ErrOutsideRoot)
+	_, err = os.Stat(outside)
+	assert.True(t, os.IsNotExist(err))
+}
+
+// TestStorage_URLFunc 验证存储后端可使用自定义 URL 函数。
+func TestStorage_URLFunc(t *testing.T) {
+	local := filer.NewLocalStorage(t.TempDir(), "https://ignored.example.com")
+	local.URLFunc = func(key string) string { return "https://img.example.com/" + key + "?v=1" }
+	assert.Equal(t, "https://img.example.com/a/b.jpg?v=1", local.URL("/a/../a/b.jpg"))
+
+	s3, err := filer.NewS3Storage(filer.S3Config{
+		Endpoint: "https://s3.example.com",
+		Bucket:   "bucket",
+		URLFunc:  func(key string) string { return "https://cdn.example.com/" + key },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/a.jpg", s3.URL("a.jpg"))
+}
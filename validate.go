@@ -0,0 +1,261 @@
+package filer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// 校验规则名称，见 Violation.Rule
+const (
+	RuleExt        = "ext"
+	RuleMIMEType   = "mime"
+	RuleMinSize    = "min_size"
+	RuleMaxSize    = "max_size"
+	RuleDimensions = "dimensions"
+	RuleName       = "name"
+)
+
+// Policy 声明式的上传校验策略，由 WithPolicy 在 Open 与 SaveTo 时应用，也可通过 Validate 单独使用。零值字段表示不限制。
+type Policy struct {
+	AllowedExts      []string       // 允许的扩展名，不区分大小写，可省略前导 "."
+	AllowedMIMETypes []string       // 允许的内容类型（按内容嗅探，忽略参数），支持 "image/*" 形式
+	MinSize          int64          // 最小字节数
+	MaxSize          int64          // 最大字节数
+	MinWidth         int            // 图片最小宽度，设置任一尺寸限制时要求内容为图片
+	MinHeight        int            // 图片最小高度
+	MaxWidth         int            // 图片最大宽度
+	MaxHeight        int            // 图片最大高度
+	NamePattern      *regexp.Regexp // 文件名（Name()，SaveTo 时为目标文件名）必须匹配
+}
+
+// Violation 一条未通过的规则
+type Violation struct {
+	Rule    string // 规则名称，如 RuleExt
+	Message string
+}
+
+// ValidationError 校验失败，列出全部未通过的规则
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return "filer: validation failed: " + strings.Join(messages, "; ")
+}
+
+// Is 使 errors.Is(err, ErrValidation) 成立
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Has 判断 rule 是否未通过
+func (e *ValidationError) Has(rule string) bool {
+	return slices.ContainsFunc(e.Violations, func(v Violation) bool { return v.Rule == rule })
+}
+
+// WithPolicy Open 成功后按 policy 校验，失败时关闭文件并返回 *ValidationError；
+// SaveTo 时再按目标文件名校验扩展名与 NamePattern，防止以其他扩展名保存。
+func WithPolicy(policy Policy) Option {
+	return func(o *options) {
+		o.policy = &policy
+	}
+}
+
+// Validate 按 policy 校验已打开的文件，失败时返回 *ValidationError。
+// 大小未知的流在校验 MinSize/MaxSize 时会缓冲为内存流（受 WithMaxSize 与 MaxSize 约束，超过 MaxSize 时流已被部分读取），
+// 其余规则只读取开头的字节；读取位置保持不变。
+func (f *Filer) Validate(policy Policy) error {
+	if err := f.validate(&policy); err != nil {
+		return f.wrapError("validate", f.sourcePath(), err)
+	}
+	return nil
+}
+
+// validate 执行 Validate
+func (f *Filer) validate(p *Policy) error {
+	if f.readCloser == nil {
+		return ErrNotOpened
+	}
+	var violations []Violation
+	add := func(rule, format string, args ...any) {
+		violations = append(violations, Violation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(p.AllowedExts) > 0 {
+		if ext := f.Ext(); !p.allowsExt(ext) {
+			add(RuleExt, "extension %q is not allowed", ext)
+		}
+	}
+	if p.NamePattern != nil && !p.NamePattern.MatchString(f.Name()) {
+		add(RuleName, "name %q does not match %s", f.Name(), p.NamePattern)
+	}
+
+	// 先于大小校验读取开头的字节，大小超过 MaxSize 时仍能校验内容类型与尺寸
+	checkMIME := len(p.AllowedMIMETypes) > 0
+	checkDimensions := p.MinWidth > 0 || p.MinHeight > 0 || p.MaxWidth > 0 || p.MaxHeight > 0
+	var (
+		mimeType string
+		cfg      image.Config
+		cfgErr   error
+	)
+	if checkMIME || checkDimensions {
+		// 与 IsImage 一致，读取前 64KiB 以便 TIFF 等格式解析尺寸
+		head, err := f.peek(64 * 1024)
+		if err != nil {
+			return err
+		}
+		if checkMIME {
+			// 不可 Seek 的流只按开头的字节识别，无法读取 ZIP 中央目录
+			r := sniffContent(head, nil, -1)
+			if _, ok := f.readCloser.(io.Seeker); ok {
+				if r, err = f.sniff(); err != nil {
+					return err
+				}
+			}
+			mimeType = mimeBaseType(r.mimeType)
+		}
+		if checkDimensions {
+			cfg, _, cfgErr = image.DecodeConfig(bytes.NewReader(head))
+		}
+	}
+
+	if p.MinSize > 0 || p.MaxSize > 0 {
+		size, err := f.sizeOf()
+		if err != nil || size < 0 {
+			// 大小未知时边读边计数，超过 MaxSize 即停止，不会把超限内容整体读入内存
+			limit := p.MaxSize
+			if _, ok := f.readCloser.(io.Seeker); !ok && limit > 0 {
+				if f.opts.maxSize > 0 && f.opts.maxSize < limit {
+					limit = f.opts.maxSize
+				}
+				f.readCloser = &limitedReadCloser{ReadCloser: f.readCloser, limit: limit}
+			}
+			if err = f.ensureSeekable(); err == nil {
+				size, err = f.sizeOf()
+			}
+			var limitErr *SizeLimitError
+			if p.MaxSize > 0 && errors.As(err, &limitErr) && limitErr.Limit == p.MaxSize {
+				// 已超过 MaxSize，实际大小未知
+				size, err = -1, nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if size < 0 {
+			add(RuleMaxSize, "size exceeds %d bytes", p.MaxSize)
+		} else {
+			if p.MinSize > 0 && size < p.MinSize {
+				add(RuleMinSize, "size %d is less than %d bytes", size, p.MinSize)
+			}
+			if p.MaxSize > 0 && size > p.MaxSize {
+				add(RuleMaxSize, "size %d exceeds %d bytes", size, p.MaxSize)
+			}
+		}
+	}
+
+	if checkMIME && !p.allowsMIMEType(mimeType) {
+		add(RuleMIMEType, "content type %q is not allowed", mimeType)
+	}
+	if checkDimensions {
+		if cfgErr != nil {
+			add(RuleDimensions, "content is not an image")
+		} else if (p.MinWidth > 0 && cfg.Width < p.MinWidth) || (p.MinHeight > 0 && cfg.Height < p.MinHeight) ||
+			(p.MaxWidth > 0 && cfg.Width > p.MaxWidth) || (p.MaxHeight > 0 && cfg.Height > p.MaxHeight) {
+			add(RuleDimensions, "dimensions %dx%d are out of bounds", cfg.Width, cfg.Height)
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// validateTarget SaveTo 时按目标文件名校验扩展名与 NamePattern
+func (f *Filer) validateTarget(filename string) error {
+	p := f.opts.policy
+	if p == nil {
+		return nil
+	}
+	var violations []Violation
+	name := filepath.Base(filename)
+	if ext := filepath.Ext(name); len(p.AllowedExts) > 0 && !p.allowsExt(ext) {
+		violations = append(violations, Violation{Rule: RuleExt, Message: fmt.Sprintf("target extension %q is not allowed", ext)})
+	}
+	if p.NamePattern != nil && !p.NamePattern.MatchString(name) {
+		violations = append(violations, Violation{Rule: RuleName, Message: fmt.Sprintf("target name %q does not match %s", name, p.NamePattern)})
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+func (p *Policy) allowsExt(ext string) bool {
+	ext = strings.TrimPrefix(strings.ToLower(ext), ".")
+	return ext != "" && slices.ContainsFunc(p.AllowedExts, func(allowed string) bool {
+		return strings.TrimPrefix(strings.ToLower(allowed), ".") == ext
+	})
+}
+
+func (p *Policy) allowsMIMEType(mimeType string) bool {
+	mimeType = strings.ToLower(mimeType)
+	for _, allowed := range p.AllowedMIMETypes {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == mimeType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(mimeType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// peek 从头读取至多 n 字节，读取位置保持不变；非 Seek 流只读取开头 n 字节，之后的读取仍从这些字节开始
+func (f *Filer) peek(n int) ([]byte, error) {
+	if f.readCloser == nil {
+		return nil, ErrNotOpened
+	}
+	seeker, ok := f.readCloser.(io.Seeker)
+	if !ok {
+		buf := make([]byte, n)
+		m, err := io.ReadFull(f.reader(), buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		f.readCloser = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(buf[:m]), f.readCloser), f.readCloser}
+		return buf[:m], nil
+	}
+	pos, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = seeker.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	m, err := io.ReadFull(f.reader(), buf)
+	if _, serr := seeker.Seek(pos, io.SeekStart); serr != nil {
+		return nil, serr
+	}
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return buf[:m], nil
+}
@@ -0,0 +1,160 @@
+package filer_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hiscaler/filer-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPolicy_Open 验证 Open 时按策略校验，并列出全部未通过的规则。
+func TestPolicy_Open(t *testing.T) {
+	dir := t.TempDir()
+	pngPath := filepath.Join(dir, "avatar.png")
+	require.NoError(t, os.WriteFile(pngPath, pngFixture(40, 30), 0644))
+
+	policy := filer.Policy{
+		AllowedExts:      []string{"PNG", ".jpg"},
+		AllowedMIMETypes: []string{"image/*"},
+		MinSize:          10,
+		MaxSize:          1 << 20,
+		MinWidth:         32,
+		MaxHeight:        64,
+		NamePattern:      regexp.MustCompile(`^[a-z0-9_-]+\.[a-z]+$`),
+	}
+	f := filer.NewFiler(filer.WithPolicy(policy))
+	require.NoError(t, f.Open(pngPath))
+	_ = f.Close()
+
+	txtPath := filepath.Join(dir, "Bad Name.txt")
+	require.NoError(t, os.WriteFile(txtPath, []byte("hi"), 0644))
+	f = filer.NewFiler(filer.WithPolicy(policy))
+	err := f.Open(txtPath)
+	var validationErr *filer.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.ErrorIs(t, err, filer.ErrValidation)
+	var rules []string
+	for _, v := range validationErr.Violations {
+		rules = append(rules, v.Rule)
+	}
+	assert.Equal(t, []string{filer.RuleExt, filer.RuleName, filer.RuleMinSize, filer.RuleMIMEType, filer.RuleDimensions}, rules)
+	// 校验失败后文件已关闭
+	_, err = f.Body()
+	assert.ErrorIs(t, err, filer.ErrNotOpened)
+}
+
+// TestPolicy_Dimensions 验证图片尺寸上下限。
+func TestPolicy_Dimensions(t *testing.T) {
+	f := filer.NewFiler()
+	require.NoError(t, f.Open(pngFixture(100, 20)))
+	err := f.Validate(filer.Policy{MaxWidth: 80, MinHeight: 10})
+	var validationErr *filer.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.True(t, validationErr.Has(filer.RuleDimensions))
+	assert.Len(t, validationErr.Violations, 1)
+
+	assert.NoError(t, f.Validate(filer.Policy{MaxWidth: 100, MaxHeight: 20}))
+	// 校验不影响之后的读取
+	body, err := f.Body()
+	require.NoError(t, err)
+	assert.Equal(t, pngFixture(100, 20), body)
+}
+
+// TestPolicy_SaveTo 验证 SaveTo 时按目标文件名校验，不能以其他扩展名保存。
+func TestPolicy_SaveTo(t *testing.T) {
+	dir := t.TempDir()
+	f := filer.NewFiler(filer.WithPolicy(filer.Policy{AllowedExts: []string{".png"}}))
+	require.NoError(t, f.Open(pngFixture(4, 4)))
+
+	_, err := f.SaveTo(filepath.Join(dir, "shell.php"))
+	assert.ErrorIs(t, err, filer.ErrValidation)
+	_, err = os.Stat(filepath.Join(dir, "shell.php"))
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = f.SaveTo(filepath.Join(dir, "ok.png"))
+	assert.NoError(t, err)
+
+	// Imager.SaveTo 同样按目标文件名校验
+	img, err := f.Imager()
+	require.NoError(t, err)
+	assert.ErrorIs(t, img.SaveTo(filepath.Join(dir, "resized.php")), filer.ErrValidation)
+	_, err = os.Stat(filepath.Join(dir, "resized.php"))
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, img.SaveTo(filepath.Join(dir, "resized.png")))
+
+	// 写入存储后端时同样按 key 的文件名校验
+	storage := filer.NewMemoryStorage("")
+	_, err = f.SaveToStorage(context.Background(), storage, "uploads/shell.php")
+	assert.ErrorIs(t, err, filer.ErrValidation)
+	assert.ErrorIs(t, img.SaveToStorage(context.Background(), storage, "uploads/shell.php"), filer.ErrValidation)
+	assert.Empty(t, storage.Keys())
+	_, err = f.SaveToStorage(context.Background(), storage, "uploads/ok.png")
+	assert.NoError(t, err)
+}
+
+// chunkedServer 以分块编码（无 Content-Length）发送 size 字节的 body，sent 在客户端断开后返回实际发送的字节数
+func chunkedServer(t *testing.T, chunk []byte, size int) (url string, sent func() int64) {
+	t.Helper()
+	var written atomic.Int64
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		w.Header().Set("Content-Type", "application/octet-stream")
+		for int(written.Load()) < size {
+			n, err := w.Write(chunk)
+			written.Add(int64(n))
+			if err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL + "/upload", func() int64 {
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatal("server did not stop sending")
+		}
+		return written.Load()
+	}
+}
+
+// TestPolicy_StreamingLimits 验证大小未知的流在超过 MaxSize 或仅需嗅探时不会被整体读取，且超过 MaxSize 时仍收集其他规则。
+func TestPolicy_StreamingLimits(t *testing.T) {
+	const total = 32 << 20
+	tests := []struct {
+		name   string
+		policy filer.Policy
+		chunk  []byte
+		rules  []string
+	}{
+		{"max size", filer.Policy{MaxSize: 1024}, bytes.Repeat([]byte{0}, 32<<10), []string{filer.RuleMaxSize}},
+		{"mime type", filer.Policy{AllowedMIMETypes: []string{"image/*"}}, bytes.Repeat([]byte("a"), 32<<10), []string{filer.RuleMIMEType}},
+		{"max size and mime type", filer.Policy{MaxSize: 10, AllowedMIMETypes: []string{"image/*"}}, bytes.Repeat([]byte("a"), 32<<10), []string{filer.RuleMaxSize, filer.RuleMIMEType}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, sent := chunkedServer(t, tt.chunk, total)
+			f := filer.NewFiler(filer.WithPolicy(tt.policy))
+			err := f.Open(url)
+			var validationErr *filer.ValidationError
+			require.True(t, errors.As(err, &validationErr), "err: %v", err)
+			for _, rule := range tt.rules {
+				assert.True(t, validationErr.Has(rule), "rule: %s", rule)
+			}
+			assert.Less(t, sent(), int64(total/4))
+		})
+	}
+}